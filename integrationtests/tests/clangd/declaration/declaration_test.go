@@ -0,0 +1,34 @@
+package declaration_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/clangd/internal"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestReadDeclaration tests that the declaration of a function resolves to its
+// prototype in the header rather than its definition in a source file
+func TestReadDeclaration(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	filePath := filepath.Join(suite.WorkspaceDir, "src/consumer.cpp")
+	result, err := tools.ReadDeclaration(ctx, suite.Client, filePath, 14, 28)
+	if err != nil {
+		t.Fatalf("ReadDeclaration failed: %v", err)
+	}
+
+	if !strings.Contains(result, "include/helper.hpp") {
+		t.Errorf("Expected declaration to be in helper.hpp but got: %s", result)
+	}
+	if !strings.Contains(result, "void helperFunction();") {
+		t.Errorf("Expected declaration to contain the prototype but got: %s", result)
+	}
+}
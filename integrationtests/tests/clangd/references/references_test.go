@@ -9,6 +9,7 @@ import (
 
 	"github.com/vector67/mcp-language-server/integrationtests/tests/clangd/internal"
 	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/tools"
 )
 
@@ -77,7 +78,7 @@ func TestFindReferences(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Call the FindReferences tool
-			result, err := tools.FindReferences(ctx, suite.Client, tc.symbolName)
+			result, err := tools.FindReferences(ctx, []*lsp.Client{suite.Client}, tc.symbolName)
 			if err != nil {
 				t.Fatalf("Failed to find references for %s: %v. Result: %s", tc.symbolName, err, result)
 			}
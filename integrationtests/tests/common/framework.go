@@ -163,6 +163,9 @@ func (ts *TestSuite) Setup() error {
 	ts.Client = client
 	ts.t.Logf("Started LSP: %s %v", ts.Config.Command, ts.Config.Args)
 
+	// Set the server up as mcp-language-server would
+	client.SetPreset(lsp.BuiltinPresets().ForCommand(ts.Config.Command))
+
 	// Initialize LSP and set up file watcher
 	initResult, err := client.InitializeLSPClient(ts.Context, workspaceDir)
 	if err != nil {
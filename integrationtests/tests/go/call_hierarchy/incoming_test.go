@@ -8,6 +8,7 @@ import (
 
 	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
 	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/tools"
 )
 
@@ -40,7 +41,7 @@ func TestIncomingCalls(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Call the GetIncomingCalls tool
-			result, err := tools.GetCallers(ctx, suite.Client, tc.symbolName, 1)
+			result, err := tools.GetCallers(ctx, []*lsp.Client{suite.Client}, tc.symbolName, 1)
 			if err != nil {
 				t.Fatalf("Failed to find incoming calls: %v", err)
 			}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
 	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/tools"
 )
 
@@ -40,7 +41,7 @@ func TestOutgoingCalls(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Call the GetOutgoingCalls tool
-			result, err := tools.GetCallees(ctx, suite.Client, tc.symbolName, 1)
+			result, err := tools.GetCallees(ctx, []*lsp.Client{suite.Client}, tc.symbolName, 1)
 			if err != nil {
 				t.Fatalf("Failed to find outgoing calls: %v", err)
 			}
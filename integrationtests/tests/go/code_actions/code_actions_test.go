@@ -0,0 +1,59 @@
+package code_actions_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestCodeActions tests listing code actions with the Go language server
+func TestCodeActions(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name         string
+		file         string
+		startLine    int
+		startColumn  int
+		endLine      int
+		endColumn    int
+		expectedText string
+		snapshotName string
+	}{
+		{
+			name:         "ExtractFunction",
+			file:         "consumer.go",
+			startLine:    7,
+			startColumn:  2,
+			endLine:      8,
+			endColumn:    22,
+			expectedText: "Extract",
+			snapshotName: "extract-function",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filePath := filepath.Join(suite.WorkspaceDir, tc.file)
+			result, err := tools.GetCodeActions(ctx, suite.Client, filePath, tc.startLine, tc.startColumn, tc.endLine, tc.endColumn)
+			if err != nil {
+				t.Fatalf("GetCodeActions failed: %v", err)
+			}
+
+			if !strings.Contains(result, tc.expectedText) {
+				t.Errorf("Expected code actions to contain %q but got: %s", tc.expectedText, result)
+			}
+
+			common.SnapshotTest(t, "go", "code_actions", tc.snapshotName, result)
+		})
+	}
+}
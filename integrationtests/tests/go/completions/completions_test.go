@@ -0,0 +1,104 @@
+package completions_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestGetCompletions tests listing completions with the Go language server
+func TestGetCompletions(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	filePath := filepath.Join(suite.WorkspaceDir, "consumer.go")
+	before, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	// Complete the members of s after "s." on the line calling s.Method()
+	result, err := tools.GetCompletions(ctx, suite.Client, filePath, 19, 16, "", 0)
+	if err != nil {
+		t.Fatalf("GetCompletions failed: %v", err)
+	}
+	for _, expected := range []string{"Method", "Process", "GetName", "Name"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected completions to contain %q but got: %s", expected, result)
+		}
+	}
+	common.SnapshotTest(t, "go", "completions", "members", result)
+
+	// A prefix narrows the list without changing the file
+	result, err = tools.GetCompletions(ctx, suite.Client, filePath, 19, 16, "Proc", 0)
+	if err != nil {
+		t.Fatalf("GetCompletions with prefix failed: %v", err)
+	}
+	if !strings.Contains(result, "Process") {
+		t.Errorf("Expected completions to contain Process but got: %s", result)
+	}
+	common.SnapshotTest(t, "go", "completions", "prefix", result)
+
+	after, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("Expected listing completions to leave the file unchanged")
+	}
+}
+
+// TestApplyCompletion tests inserting a completion that needs an import
+func TestApplyCompletion(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	filePath := filepath.Join(suite.WorkspaceDir, "completion.go")
+	content := "package main\n\nfunc completionTarget() string {\n\treturn \n}\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	defer func() {
+		_ = os.Remove(filePath)
+	}()
+
+	listing, err := tools.GetCompletions(ctx, suite.Client, filePath, 4, 9, "strings.ToUp", 1)
+	if err != nil {
+		t.Fatalf("GetCompletions failed: %v", err)
+	}
+	if !strings.Contains(listing, "[1] ToUpper") {
+		t.Fatalf("Expected ToUpper to be the first completion but got: %s", listing)
+	}
+
+	result, err := tools.ApplyCompletion(ctx, suite.Client, filePath, 4, 9, "strings.ToUp", 1)
+	if err != nil {
+		t.Fatalf("ApplyCompletion failed: %v", err)
+	}
+	if !strings.Contains(result, "Successfully applied completion") {
+		t.Errorf("Expected success message but got: %s", result)
+	}
+
+	updated, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if !strings.Contains(string(updated), "strings.ToUpper(") {
+		t.Errorf("Expected the completion to be inserted but got: %s", updated)
+	}
+	if !strings.Contains(string(updated), "import \"strings\"") {
+		t.Errorf("Expected the import to be added but got: %s", updated)
+	}
+
+	common.SnapshotTest(t, "go", "completions", "apply-with-import", string(updated))
+}
@@ -0,0 +1,35 @@
+package document_symbols_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestDocumentSymbols tests outlining a file with the Go language server
+func TestDocumentSymbols(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	filePath := filepath.Join(suite.WorkspaceDir, "types.go")
+	result, err := tools.GetDocumentSymbols(ctx, suite.Client, filePath)
+	if err != nil {
+		t.Fatalf("GetDocumentSymbols failed: %v", err)
+	}
+
+	for _, expected := range []string{"Struct SharedStruct", "  Field Name", "Interface SharedInterface", "Constant SharedConstant"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected outline to contain %q but got: %s", expected, result)
+		}
+	}
+
+	common.SnapshotTest(t, "go", "document_symbols", "types", result)
+}
@@ -0,0 +1,52 @@
+package formatting_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestFormatDocument tests formatting a file with the Go language server
+func TestFormatDocument(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	filePath := filepath.Join(suite.WorkspaceDir, "helper.go")
+
+	// Break the gofmt formatting of the function body
+	err := suite.WriteFile("helper.go", "package main\n\n// HelperFunction returns a string for testing\nfunc HelperFunction() string {\n    return    \"hello world\"\n}\n")
+	if err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := suite.Client.NotifyChange(ctx, filePath); err != nil {
+		t.Fatalf("Failed to notify change: %v", err)
+	}
+
+	result, err := tools.FormatDocument(ctx, suite.Client, filePath)
+	if err != nil {
+		t.Fatalf("FormatDocument failed: %v", err)
+	}
+
+	if !strings.Contains(result, "1 lines changed") {
+		t.Errorf("Expected one changed line but got: %s", result)
+	}
+
+	content, err := suite.ReadFile("helper.go")
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	if !strings.Contains(content, "\treturn \"hello world\"\n") {
+		t.Errorf("File was not formatted: %s", content)
+	}
+
+	common.SnapshotTest(t, "go", "formatting", "format-document", result)
+}
@@ -0,0 +1,84 @@
+package implementations_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestFindImplementations tests finding the implementations of an interface by name
+func TestFindImplementations(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	result, err := tools.FindImplementations(ctx, []*lsp.Client{suite.Client}, "SharedInterface")
+	if err != nil {
+		t.Fatalf("FindImplementations failed: %v", err)
+	}
+
+	if !strings.Contains(result, "type SharedStruct struct") {
+		t.Errorf("Expected implementations to contain SharedStruct but got: %s", result)
+	}
+	if strings.Contains(result, "type SharedInterface interface") {
+		t.Errorf("Expected implementations not to contain the interface itself but got: %s", result)
+	}
+}
+
+// TestFindImplementationsAtPosition tests finding the implementations of an interface method by position
+func TestFindImplementationsAtPosition(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name         string
+		file         string
+		line         int
+		column       int
+		expectedText string
+		snapshotName string
+	}{
+		{
+			name:         "InterfaceMethod",
+			file:         "types.go",
+			line:         20,
+			column:       2,
+			expectedText: "func (s *SharedStruct) Process() error",
+			snapshotName: "interface-method",
+		},
+		{
+			name:         "NoImplementations",
+			file:         "types.go",
+			line:         28,
+			column:       6,
+			expectedText: "No implementations found",
+			snapshotName: "not-found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filePath := filepath.Join(suite.WorkspaceDir, tc.file)
+			result, err := tools.FindImplementationsAtPosition(ctx, suite.Client, filePath, tc.line, tc.column)
+			if err != nil {
+				t.Fatalf("FindImplementationsAtPosition failed: %v", err)
+			}
+
+			if !strings.Contains(result, tc.expectedText) {
+				t.Errorf("Expected result to contain %q but got: %s", tc.expectedText, result)
+			}
+
+			common.SnapshotTest(t, "go", "implementations", tc.snapshotName, result)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package read_with_hints_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestReadWithHints tests reading a range of lines with inlay hints inlined
+func TestReadWithHints(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	filePath := filepath.Join(suite.WorkspaceDir, "consumer.go")
+	result, err := tools.ReadWithHints(ctx, suite.Client, filePath, 6, 8)
+	if err != nil {
+		t.Fatalf("ReadWithHints failed: %v", err)
+	}
+
+	if !strings.Contains(result, "Lines: 6-8") {
+		t.Errorf("Expected the line range in the header but got: %s", result)
+	}
+	// The inferred type of message is shown after the variable
+	if !strings.Contains(result, "7|\tmessage «string» := HelperFunction()") {
+		t.Errorf("Expected the inferred type of message to be inlined but got: %s", result)
+	}
+	if strings.Contains(result, "SharedStruct") {
+		t.Errorf("Expected only lines 6-8 but got: %s", result)
+	}
+
+	common.SnapshotTest(t, "go", "read_with_hints", "inferred-types", result)
+}
+
+// TestReadWithHintsInvalidRange tests that a start line past the end is rejected
+func TestReadWithHintsInvalidRange(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	filePath := filepath.Join(suite.WorkspaceDir, "consumer.go")
+	if _, err := tools.ReadWithHints(ctx, suite.Client, filePath, 1000, 0); err == nil {
+		t.Errorf("Expected an error for a start line past the end of the file")
+	}
+}
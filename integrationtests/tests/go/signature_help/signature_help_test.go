@@ -0,0 +1,65 @@
+package signature_help_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestSignatureHelp tests signature help at call sites with the Go language server
+func TestSignatureHelp(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name         string
+		file         string
+		line         int
+		column       int
+		expectedText []string
+		snapshotName string
+	}{
+		{
+			name:         "StandardLibraryCall",
+			file:         "consumer.go",
+			line:         8,
+			column:       14,
+			expectedText: []string{"Println(", "**a ...any**", "> a ...any"},
+			snapshotName: "standard-library-call",
+		},
+		{
+			name:         "NotInCall",
+			file:         "helper.go",
+			line:         1,
+			column:       1,
+			expectedText: []string{"No signature help available"},
+			snapshotName: "not-in-call",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filePath := filepath.Join(suite.WorkspaceDir, tc.file)
+			result, err := tools.GetSignatureHelp(ctx, suite.Client, filePath, tc.line, tc.column)
+			if err != nil {
+				t.Fatalf("GetSignatureHelp failed: %v", err)
+			}
+
+			for _, expected := range tc.expectedText {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Expected signature help to contain %q but got: %s", expected, result)
+				}
+			}
+
+			common.SnapshotTest(t, "go", "signature_help", tc.snapshotName, result)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package type_definition_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestReadTypeDefinition tests jumping from a variable to the definition of its type
+func TestReadTypeDefinition(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name         string
+		file         string
+		line         int
+		column       int
+		expectedText string
+		snapshotName string
+	}{
+		{
+			name:         "StructVariable",
+			file:         "consumer.go",
+			line:         11,
+			column:       2,
+			expectedText: "type SharedStruct struct",
+			snapshotName: "struct-variable",
+		},
+		{
+			name:         "NamedTypeVariable",
+			file:         "consumer.go",
+			line:         27,
+			column:       6,
+			expectedText: "type SharedType int",
+			snapshotName: "named-type-variable",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filePath := filepath.Join(suite.WorkspaceDir, tc.file)
+			result, err := tools.ReadTypeDefinition(ctx, suite.Client, filePath, tc.line, tc.column)
+			if err != nil {
+				t.Fatalf("ReadTypeDefinition failed: %v", err)
+			}
+
+			if !strings.Contains(result, tc.expectedText) {
+				t.Errorf("Expected type definition to contain %q but got: %s", tc.expectedText, result)
+			}
+			if !strings.Contains(result, "types.go") {
+				t.Errorf("Expected type definition to be in types.go but got: %s", result)
+			}
+
+			common.SnapshotTest(t, "go", "type_definition", tc.snapshotName, result)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package type_hierarchy_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestTypeHierarchy tests walking the type hierarchy with the Go language server
+func TestTypeHierarchy(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name           string
+		symbolName     string
+		direction      string
+		expectedText   []string
+		unexpectedText []string
+		snapshotName   string
+	}{
+		{
+			name:           "Supertypes",
+			symbolName:     "SharedStruct",
+			direction:      tools.TypeHierarchyUp,
+			expectedText:   []string{"Name: SharedStruct", "- Supertype: SharedInterface"},
+			unexpectedText: []string{"Subtype:"},
+			snapshotName:   "supertypes",
+		},
+		{
+			name:           "Subtypes",
+			symbolName:     "SharedInterface",
+			direction:      tools.TypeHierarchyDown,
+			expectedText:   []string{"Name: SharedInterface", "- Subtype: SharedStruct", "types.go:6"},
+			unexpectedText: []string{"Supertype:"},
+			snapshotName:   "subtypes",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tools.GetTypeHierarchy(ctx, []*lsp.Client{suite.Client}, tc.symbolName, tc.direction, tools.DefaultTypeHierarchyDepth)
+			if err != nil {
+				t.Fatalf("GetTypeHierarchy failed: %v", err)
+			}
+
+			for _, expected := range tc.expectedText {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Expected type hierarchy to contain %q but got: %s", expected, result)
+				}
+			}
+			for _, unexpected := range tc.unexpectedText {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("Expected type hierarchy not to contain %q but got: %s", unexpected, result)
+				}
+			}
+
+			common.SnapshotTest(t, "go", "type_hierarchy", tc.snapshotName, result)
+		})
+	}
+}
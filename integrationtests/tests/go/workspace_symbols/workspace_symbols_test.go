@@ -0,0 +1,85 @@
+package workspace_symbols_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/integrationtests/tests/common"
+	"github.com/vector67/mcp-language-server/integrationtests/tests/go/internal"
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/tools"
+)
+
+// TestWorkspaceSymbols tests searching workspace symbols with the Go language server
+func TestWorkspaceSymbols(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name           string
+		query          string
+		kinds          []string
+		pathGlob       string
+		expectedText   []string
+		unexpectedText []string
+		snapshotName   string
+	}{
+		{
+			name:  "FuzzyQuery",
+			query: "Shared",
+			// gopls also matches standard library symbols once it has indexed
+			// them, so keep to the workspace for a stable snapshot
+			pathGlob:     "types.go",
+			expectedText: []string{"SharedStruct (Struct)", "SharedInterface (Interface)", "SharedConstant (Constant)"},
+			snapshotName: "fuzzy",
+		},
+		{
+			name:           "KindFilter",
+			query:          "Shared",
+			kinds:          []string{"Interface"},
+			expectedText:   []string{"SharedInterface (Interface)"},
+			unexpectedText: []string{"SharedStruct"},
+			snapshotName:   "kind-filter",
+		},
+		{
+			name:           "PathFilter",
+			query:          "Function",
+			pathGlob:       "consumer.go",
+			expectedText:   []string{"ConsumerFunction"},
+			unexpectedText: []string{"HelperFunction"},
+			snapshotName:   "path-filter",
+		},
+		{
+			name:         "NotFound",
+			query:        "NotARealSymbolName",
+			expectedText: []string{"No symbols found"},
+			snapshotName: "not-found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tools.SearchWorkspaceSymbols(ctx, []*lsp.Client{suite.Client}, tc.query, tc.kinds, tc.pathGlob, 0)
+			if err != nil {
+				t.Fatalf("SearchWorkspaceSymbols failed: %v", err)
+			}
+
+			for _, expected := range tc.expectedText {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Expected result to contain %q but got: %s", expected, result)
+				}
+			}
+			for _, unexpected := range tc.unexpectedText {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("Expected result not to contain %q but got: %s", unexpected, result)
+				}
+			}
+
+			common.SnapshotTest(t, "go", "workspace_symbols", tc.snapshotName, result)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package lsp
+
+import (
+	"runtime/debug"
+	"slices"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// clientCapabilities describes what this client implements. Every capability
+// here is backed by a tool or a handler: servers use the declaration to decide
+// which features to offer and in what form, so declaring something the client
+// doesn't handle makes servers send requests that fail, and leaving something
+// out makes them fall back to older behaviour.
+//
+// Capabilities that let the server send requests to the client are listed in
+// serverRequestCapabilities, and must match the handlers registered by
+// registerHandlers.
+func clientCapabilities() protocol.ClientCapabilities {
+	failureHandling := protocol.Abort
+
+	return protocol.ClientCapabilities{
+		Workspace: protocol.WorkspaceClientCapabilities{
+			ApplyEdit: true,
+			WorkspaceEdit: &protocol.WorkspaceEditClientCapabilities{
+				DocumentChanges: true,
+				// utilities.ApplyDocumentChange implements all three
+				ResourceOperations: []protocol.ResourceOperationKind{protocol.Create, protocol.Rename, protocol.Delete},
+				// Changes are applied in order and stop at the first failure,
+				// keeping the ones before it
+				FailureHandling: &failureHandling,
+			},
+			Configuration: true,
+			DidChangeConfiguration: protocol.DidChangeConfigurationClientCapabilities{
+				DynamicRegistration: true,
+			},
+			DidChangeWatchedFiles: protocol.DidChangeWatchedFilesClientCapabilities{
+				DynamicRegistration:    true,
+				RelativePatternSupport: true,
+			},
+			Symbol: &protocol.WorkspaceSymbolClientCapabilities{
+				SymbolKind: &protocol.ClientSymbolKindOptions{
+					ValueSet: allSymbolKinds(),
+				},
+				ResolveSupport: &protocol.ClientSymbolResolveOptions{
+					Properties: []string{"location.range"},
+				},
+			},
+			ExecuteCommand:   &protocol.ExecuteCommandClientCapabilities{},
+			WorkspaceFolders: true,
+			Diagnostics: &protocol.DiagnosticWorkspaceClientCapabilities{
+				RefreshSupport: true,
+			},
+		},
+		TextDocument: protocol.TextDocumentClientCapabilities{
+			Synchronization: &protocol.TextDocumentSyncClientCapabilities{
+				DynamicRegistration: true,
+				DidSave:             true,
+			},
+			Hover: &protocol.HoverClientCapabilities{
+				ContentFormat: []protocol.MarkupKind{protocol.Markdown, protocol.PlainText},
+			},
+			SignatureHelp: &protocol.SignatureHelpClientCapabilities{
+				SignatureInformation: &protocol.ClientSignatureInformationOptions{
+					DocumentationFormat: []protocol.MarkupKind{protocol.Markdown, protocol.PlainText},
+					ParameterInformation: &protocol.ClientSignatureParameterInformationOptions{
+						LabelOffsetSupport: true,
+					},
+					ActiveParameterSupport: true,
+				},
+				ContextSupport: true,
+			},
+			Declaration: &protocol.DeclarationClientCapabilities{
+				LinkSupport: true,
+			},
+			TypeDefinition: &protocol.TypeDefinitionClientCapabilities{
+				LinkSupport: true,
+			},
+			Implementation: &protocol.ImplementationClientCapabilities{
+				LinkSupport: true,
+			},
+			References: &protocol.ReferenceClientCapabilities{},
+			Completion: protocol.CompletionClientCapabilities{
+				CompletionItem: protocol.ClientCompletionItemOptions{
+					SnippetSupport:          true,
+					CommitCharactersSupport: true,
+					DocumentationFormat:     []protocol.MarkupKind{protocol.Markdown, protocol.PlainText},
+					DeprecatedSupport:       true,
+					TagSupport: &protocol.CompletionItemTagOptions{
+						ValueSet: []protocol.CompletionItemTag{protocol.ComplDeprecated},
+					},
+					InsertReplaceSupport: true,
+					ResolveSupport: &protocol.ClientCompletionItemResolveOptions{
+						Properties: []string{"documentation", "detail", "additionalTextEdits"},
+					},
+					LabelDetailsSupport: true,
+				},
+				CompletionItemKind: &protocol.ClientCompletionItemOptionsKind{
+					ValueSet: allCompletionItemKinds(),
+				},
+				ContextSupport: true,
+				CompletionList: &protocol.CompletionListCapabilities{
+					ItemDefaults: []string{"editRange", "insertTextFormat", "insertTextMode", "data"},
+				},
+			},
+			CodeLens: &protocol.CodeLensClientCapabilities{
+				DynamicRegistration: true,
+			},
+			DocumentSymbol: protocol.DocumentSymbolClientCapabilities{
+				SymbolKind: &protocol.ClientSymbolKindOptions{
+					ValueSet: allSymbolKinds(),
+				},
+				HierarchicalDocumentSymbolSupport: true,
+			},
+			CodeAction: protocol.CodeActionClientCapabilities{
+				CodeActionLiteralSupport: protocol.ClientCodeActionLiteralOptions{
+					CodeActionKind: protocol.ClientCodeActionKindOptions{
+						ValueSet: []protocol.CodeActionKind{
+							protocol.Empty,
+							protocol.QuickFix,
+							protocol.Refactor,
+							protocol.RefactorExtract,
+							protocol.RefactorInline,
+							protocol.RefactorRewrite,
+							protocol.Source,
+							protocol.SourceOrganizeImports,
+							protocol.SourceFixAll,
+						},
+					},
+				},
+				IsPreferredSupport: true,
+				DisabledSupport:    true,
+				DataSupport:        true,
+				ResolveSupport: &protocol.ClientCodeActionResolveOptions{
+					Properties: []string{"edit"},
+				},
+			},
+			Formatting:      &protocol.DocumentFormattingClientCapabilities{},
+			RangeFormatting: &protocol.DocumentRangeFormattingClientCapabilities{},
+			Rename: &protocol.RenameClientCapabilities{
+				PrepareSupport: true,
+			},
+			CallHierarchy: &protocol.CallHierarchyClientCapabilities{},
+			TypeHierarchy: &protocol.TypeHierarchyClientCapabilities{},
+			InlayHint: &protocol.InlayHintClientCapabilities{
+				ResolveSupport: &protocol.ClientInlayHintResolveOptions{
+					Properties: []string{"tooltip", "textEdits", "label.tooltip", "label.location", "label.command"},
+				},
+			},
+			PublishDiagnostics: protocol.PublishDiagnosticsClientCapabilities{
+				VersionSupport: true,
+			},
+			Diagnostic: &protocol.DiagnosticClientCapabilities{},
+		},
+		Window: protocol.WindowClientCapabilities{
+			WorkDoneProgress: true,
+			ShowMessage:      &protocol.ShowMessageRequestClientCapabilities{},
+			ShowDocument: &protocol.ShowDocumentClientCapabilities{
+				Support: true,
+			},
+		},
+		General: &protocol.GeneralClientCapabilities{
+			// In order of preference. UTF-8 saves converting
+			// positions, UTF-16 is the default every server supports.
+			PositionEncodings: []protocol.PositionEncodingKind{protocol.UTF8, protocol.UTF16},
+		},
+	}
+}
+
+// serverRequestCapabilities maps each request the server can send to the
+// client to the capability that allows it to
+var serverRequestCapabilities = map[string]func(protocol.ClientCapabilities) bool{
+	"workspace/applyEdit": func(caps protocol.ClientCapabilities) bool {
+		return caps.Workspace.ApplyEdit
+	},
+	"workspace/configuration": func(caps protocol.ClientCapabilities) bool {
+		return caps.Workspace.Configuration
+	},
+	"workspace/workspaceFolders": func(caps protocol.ClientCapabilities) bool {
+		return caps.Workspace.WorkspaceFolders
+	},
+	"workspace/diagnostic/refresh": func(caps protocol.ClientCapabilities) bool {
+		return caps.Workspace.Diagnostics != nil && caps.Workspace.Diagnostics.RefreshSupport
+	},
+	"client/registerCapability": func(caps protocol.ClientCapabilities) bool {
+		return caps.Workspace.DidChangeWatchedFiles.DynamicRegistration
+	},
+	"client/unregisterCapability": func(caps protocol.ClientCapabilities) bool {
+		return caps.Workspace.DidChangeWatchedFiles.DynamicRegistration
+	},
+	"window/workDoneProgress/create": func(caps protocol.ClientCapabilities) bool {
+		return caps.Window.WorkDoneProgress
+	},
+	"window/showMessageRequest": func(caps protocol.ClientCapabilities) bool {
+		return caps.Window.ShowMessage != nil
+	},
+	"window/showDocument": func(caps protocol.ClientCapabilities) bool {
+		return caps.Window.ShowDocument != nil && caps.Window.ShowDocument.Support
+	},
+}
+
+// clientVersion returns the version of this binary as recorded by the Go
+// toolchain: the module version for go install, or a pseudo-version for
+// builds from a checkout
+func clientVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "(devel)"
+	}
+	return info.Main.Version
+}
+
+// allSymbolKinds returns every SymbolKind the client knows how to display
+func allSymbolKinds() []protocol.SymbolKind {
+	kinds := make([]protocol.SymbolKind, 0, len(protocol.TableKindMap))
+	for kind := range protocol.TableKindMap {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// allCompletionItemKinds returns every CompletionItemKind the client knows how to display
+func allCompletionItemKinds() []protocol.CompletionItemKind {
+	kinds := make([]protocol.CompletionItemKind, 0, len(protocol.TableCompletionKindMap))
+	for kind := range protocol.TableCompletionKindMap {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
@@ -0,0 +1,69 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// TestClientCapabilities_MatchHandlers checks that the client declares a
+// capability for each request it handles from the server, and handles each
+// request its capabilities allow the server to send
+func TestClientCapabilities_MatchHandlers(t *testing.T) {
+	client := &Client{
+		serverRequestHandlers: make(map[string]ServerRequestHandler),
+		notificationHandlers:  make(map[string]NotificationHandler),
+	}
+	client.registerHandlers()
+	caps := clientCapabilities()
+
+	for method, declared := range serverRequestCapabilities {
+		_, handled := client.serverRequestHandlers[method]
+		switch {
+		case declared(caps) && !handled:
+			t.Errorf("capability for %s is declared but no handler is registered", method)
+		case !declared(caps) && handled:
+			t.Errorf("%s is handled but its capability is not declared", method)
+		}
+	}
+
+	for method := range client.serverRequestHandlers {
+		if _, ok := serverRequestCapabilities[method]; !ok {
+			t.Errorf("%s is handled but missing from serverRequestCapabilities", method)
+		}
+	}
+}
+
+func TestClientCapabilities(t *testing.T) {
+	caps := clientCapabilities()
+
+	edit := caps.Workspace.WorkspaceEdit
+	if edit == nil || !edit.DocumentChanges {
+		t.Fatal("expected workspaceEdit.documentChanges to be declared")
+	}
+	for _, kind := range []protocol.ResourceOperationKind{protocol.Create, protocol.Rename, protocol.Delete} {
+		found := false
+		for _, declared := range edit.ResourceOperations {
+			found = found || declared == kind
+		}
+		if !found {
+			t.Errorf("expected resource operation %s to be declared", kind)
+		}
+	}
+	if edit.FailureHandling == nil || *edit.FailureHandling != protocol.Abort {
+		t.Errorf("expected failure handling abort, got %v", edit.FailureHandling)
+	}
+
+	if caps.TextDocument.Rename == nil || !caps.TextDocument.Rename.PrepareSupport {
+		t.Error("expected rename.prepareSupport to be declared")
+	}
+	if !caps.TextDocument.DocumentSymbol.HierarchicalDocumentSymbolSupport {
+		t.Error("expected hierarchical document symbols to be declared")
+	}
+	if caps.TextDocument.CallHierarchy == nil || caps.TextDocument.TypeHierarchy == nil {
+		t.Error("expected call and type hierarchy to be declared")
+	}
+	if caps.TextDocument.Diagnostic == nil {
+		t.Error("expected diagnostic pull to be declared")
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -17,10 +16,28 @@ import (
 )
 
 type Client struct {
-	Cmd    *exec.Cmd
-	stdin  io.WriteCloser
-	stdout *bufio.Reader
-	stderr io.ReadCloser
+	// Starts or connects to the server, again whenever it is restarted
+	transport Transport
+
+	// Connection to the current server, and where messages to it are written
+	conn   Conn
+	writer io.Writer
+
+	// Guards the connection fields above and below, which are replaced when
+	// the server is restarted
+	processMu sync.RWMutex
+	// Closed once the current server process has exited
+	exited chan struct{}
+	// Error the process exited with, valid once exited is closed
+	exitErr error
+	// Set once the server has crashed and could not be restarted. Requests
+	// fail with it instead of ErrServerExited.
+	failure error
+	// Set by Close so that the server exiting is not treated as a crash
+	closing atomic.Bool
+
+	// Serializes writes of messages to the server
+	writeMu sync.Mutex
 
 	// Request ID counter
 	nextID atomic.Int32
@@ -48,62 +65,176 @@ type Client struct {
 	// Files are currently opened by the LSP
 	openFiles   map[string]*OpenFileInfo
 	openFilesMu sync.RWMutex
+	// Limit on open files, beyond which the least recently used are closed
+	maxOpenFiles int
+	// Incremented on every use of an open file, to order them by recency
+	useClock uint64
+	// Number of pending operations on each file, which stop it being evicted
+	pins map[string]int
+	// Files opened when the server started rather than for a request
+	startupFiles map[string]struct{}
+	// Serializes didOpen and didClose so that an evicted file being
+	// reopened reaches the server in order
+	syncMu    sync.Mutex
+	evictions atomic.Uint64
+	overflows atomic.Uint64
+
+	// Operations the server reports progress for, keyed by progress token
+	progress   map[string]*workDoneProgress
+	progressMu sync.Mutex
+	// Closed and replaced whenever an operation begins or ends
+	progressChanged chan struct{}
+	// Called with every progress update, keyed by registration
+	progressListeners    map[int]func(ProgressUpdate)
+	nextProgressListener int
+
+	// Settings from the settings file, served through workspace/configuration
+	settings   map[string]any
+	settingsMu sync.RWMutex
+	// Preset the server was started from, if any
+	preset *Preset
+	// Set once the server has been sent initialized
+	initialized atomic.Bool
+
+	// Capabilities registered by the server after initialization, by ID
+	registrations   map[string]protocol.Registration
+	registrationsMu sync.RWMutex
+	// Called when the server registers and unregisters file watchers
+	fileWatchHandler   FileWatchHandler
+	fileUnwatchHandler FileUnwatchHandler
+
+	// Workspace folders sent with initialize, for workspace/workspaceFolders
+	workspaceFolders []protocol.WorkspaceFolder
+	// Project roots to send as the workspace folders, if not the workspace
+	roots       []string
+	workspaceMu sync.RWMutex
+
+	// Capabilities reported by the server in its initialize response
+	serverCapabilities   protocol.ServerCapabilities
+	serverCapabilitiesMu sync.RWMutex
 }
 
+// NewClient starts a language server process and returns a client for it
 func NewClient(command string, args ...string) (*Client, error) {
-	cmd := exec.Command(command, args...)
-	// Copy env
-	cmd.Env = os.Environ()
-
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
-	}
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
-	}
-
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
-	}
+	return NewClientWithTransport(ProcessTransport{Command: command, Args: args})
+}
 
+// NewClientWithTransport returns a client for the language server transport
+// connects to
+func NewClientWithTransport(transport Transport) (*Client, error) {
 	client := &Client{
-		Cmd:                   cmd,
-		stdin:                 stdin,
-		stdout:                bufio.NewReader(stdout),
-		stderr:                stderr,
+		transport:             transport,
 		handlers:              make(map[string]chan *Message),
 		notificationHandlers:  make(map[string]NotificationHandler),
 		serverRequestHandlers: make(map[string]ServerRequestHandler),
 		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic),
 		diagnosticWaiters:     make(map[protocol.DocumentUri][]chan struct{}),
 		openFiles:             make(map[string]*OpenFileInfo),
+		maxOpenFiles:          DefaultMaxOpenFiles,
+		pins:                  make(map[string]int),
+		progress:              make(map[string]*workDoneProgress),
+		progressChanged:       make(chan struct{}),
+	}
+
+	if err := client.start(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Name describes the server for messages about it: the name of its command,
+// or the address it was connected to
+func (c *Client) Name() string {
+	if c.transport == nil {
+		return "language server"
 	}
+	return c.transport.String()
+}
+
+// OwnsServer reports whether the client started the server, rather than
+// connecting to one that was already running. Only servers the client owns
+// are shut down when it closes.
+func (c *Client) OwnsServer() bool {
+	return c.transport == nil || c.transport.Owned()
+}
 
-	// Start the LSP server process
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start LSP server: %w", err)
+// processID is the process ID sent in initialize, which servers watch to
+// exit when their parent does. Servers the client connected to outlive it,
+// so they are sent none.
+func (c *Client) processID() int32 {
+	if !c.OwnsServer() {
+		return 0
 	}
+	return int32(os.Getpid())
+}
+
+// start connects to the server and begins handling its messages
+func (c *Client) start() error {
+	conn, err := c.transport.Connect()
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	exited := make(chan struct{})
+	c.initialized.Store(false)
 
-	// Handle stderr in a separate goroutine with proper logging
+	c.processMu.Lock()
+	c.conn = conn
+	c.writer = conn
+	c.exited = exited
+	c.processMu.Unlock()
+
+	// Start message handling loop, then reap the process once its output ends
 	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			processLogger.Info("%s", line)
-		}
-		if err := scanner.Err(); err != nil {
-			lspLogger.Error("Error reading LSP server stderr: %v", err)
+		c.handleMessages(reader)
+
+		err := conn.Wait()
+		if err != nil {
+			lspLogger.Info("LSP server %s exited: %v", c.Name(), err)
+		} else if c.transport.Owned() {
+			lspLogger.Info("LSP server %s exited", c.Name())
+		} else {
+			lspLogger.Info("Disconnected from LSP server %s", c.Name())
 		}
+
+		c.processMu.Lock()
+		c.exitErr = err
+		c.processMu.Unlock()
+		close(exited)
 	}()
 
-	// Start message handling loop
-	go client.handleMessages()
+	return nil
+}
 
-	return client, nil
+// Exited returns a channel that is closed once the current server process
+// has exited
+func (c *Client) Exited() <-chan struct{} {
+	c.processMu.RLock()
+	defer c.processMu.RUnlock()
+	return c.exited
+}
+
+// ExitError returns the error the last server process exited with
+func (c *Client) ExitError() error {
+	c.processMu.RLock()
+	defer c.processMu.RUnlock()
+	return c.exitErr
+}
+
+// Failure returns why the server was given up on after crashing, or nil
+func (c *Client) Failure() error {
+	c.processMu.RLock()
+	defer c.processMu.RUnlock()
+	return c.failure
+}
+
+// fail marks the client as failed, so that requests return err
+func (c *Client) fail(err error) {
+	c.processMu.Lock()
+	defer c.processMu.Unlock()
+	c.failure = err
 }
 
 func (c *Client) RegisterNotificationHandler(method string, handler NotificationHandler) {
@@ -118,115 +249,205 @@ func (c *Client) RegisterServerRequestHandler(method string, handler ServerReque
 	c.serverRequestHandlers[method] = handler
 }
 
+// registerHandlers registers the handlers for requests and notifications
+// from the server. The requests must match serverRequestCapabilities.
+func (c *Client) registerHandlers() {
+	c.RegisterServerRequestHandler("workspace/applyEdit",
+		func(params json.RawMessage) (any, error) { return HandleApplyEdit(c, params) })
+	c.RegisterServerRequestHandler("workspace/configuration",
+		func(params json.RawMessage) (any, error) { return HandleWorkspaceConfiguration(c, params) })
+	c.RegisterServerRequestHandler("workspace/workspaceFolders",
+		func(params json.RawMessage) (any, error) { return c.WorkspaceFolders(), nil })
+	c.RegisterServerRequestHandler("workspace/diagnostic/refresh", HandleDiagnosticRefresh)
+	c.RegisterServerRequestHandler("client/registerCapability",
+		func(params json.RawMessage) (any, error) { return HandleRegisterCapability(c, params) })
+	c.RegisterServerRequestHandler("client/unregisterCapability",
+		func(params json.RawMessage) (any, error) { return HandleUnregisterCapability(c, params) })
+	c.RegisterServerRequestHandler("window/workDoneProgress/create",
+		func(params json.RawMessage) (any, error) { return HandleWorkDoneProgressCreate(c, params) })
+	c.RegisterServerRequestHandler("window/showMessageRequest", HandleShowMessageRequest)
+	c.RegisterServerRequestHandler("window/showDocument", HandleShowDocument)
+	c.RegisterNotificationHandler("window/showMessage", HandleServerMessage)
+	c.RegisterNotificationHandler("window/logMessage", HandleLogMessage)
+	c.RegisterNotificationHandler("textDocument/publishDiagnostics",
+		func(params json.RawMessage) { HandleDiagnostics(c, params) })
+}
+
 func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (*protocol.InitializeResult, error) {
+	roots := c.rootDirs(workspaceDir)
+	folders := make([]protocol.WorkspaceFolder, len(roots))
+	for i, root := range roots {
+		folders[i] = protocol.WorkspaceFolder{
+			URI:  protocol.URI("file://" + root),
+			Name: root,
+		}
+	}
+
 	initParams := &protocol.InitializeParams{
 		WorkspaceFoldersInitializeParams: protocol.WorkspaceFoldersInitializeParams{
-			WorkspaceFolders: []protocol.WorkspaceFolder{
-				{
-					URI:  protocol.URI("file://" + workspaceDir),
-					Name: workspaceDir,
-				},
-			},
+			WorkspaceFolders: folders,
 		},
 
 		XInitializeParams: protocol.XInitializeParams{
-			ProcessID: int32(os.Getpid()),
+			ProcessID: c.processID(),
 			ClientInfo: &protocol.ClientInfo{
 				Name:    "mcp-language-server",
-				Version: "0.1.0",
-			},
-			RootPath: workspaceDir,
-			RootURI:  protocol.DocumentUri("file://" + workspaceDir),
-			Capabilities: protocol.ClientCapabilities{
-				Workspace: protocol.WorkspaceClientCapabilities{
-					Configuration: true,
-					DidChangeConfiguration: protocol.DidChangeConfigurationClientCapabilities{
-						DynamicRegistration: true,
-					},
-					DidChangeWatchedFiles: protocol.DidChangeWatchedFilesClientCapabilities{
-						DynamicRegistration:    true,
-						RelativePatternSupport: true,
-					},
-				},
-				TextDocument: protocol.TextDocumentClientCapabilities{
-					Synchronization: &protocol.TextDocumentSyncClientCapabilities{
-						DynamicRegistration: true,
-						DidSave:             true,
-					},
-					Completion: protocol.CompletionClientCapabilities{
-						CompletionItem: protocol.ClientCompletionItemOptions{},
-					},
-					CodeLens: &protocol.CodeLensClientCapabilities{
-						DynamicRegistration: true,
-					},
-					DocumentSymbol: protocol.DocumentSymbolClientCapabilities{},
-					CodeAction: protocol.CodeActionClientCapabilities{
-						CodeActionLiteralSupport: protocol.ClientCodeActionLiteralOptions{
-							CodeActionKind: protocol.ClientCodeActionKindOptions{
-								ValueSet: []protocol.CodeActionKind{},
-							},
-						},
-					},
-					PublishDiagnostics: protocol.PublishDiagnosticsClientCapabilities{
-						VersionSupport: true,
-					},
-					SemanticTokens: protocol.SemanticTokensClientCapabilities{
-						Requests: protocol.ClientSemanticTokensRequestOptions{
-							Range: &protocol.Or_ClientSemanticTokensRequestOptions_range{},
-							Full:  &protocol.Or_ClientSemanticTokensRequestOptions_full{},
-						},
-						TokenTypes:     []string{},
-						TokenModifiers: []string{},
-						Formats:        []protocol.TokenFormat{},
-					},
-				},
-				Window: protocol.WindowClientCapabilities{},
-			},
-			InitializationOptions: map[string]any{
-				"codelenses": map[string]bool{
-					"generate":           true,
-					"regenerate_cgo":     true,
-					"test":               true,
-					"tidy":               true,
-					"upgrade_dependency": true,
-					"vendor":             true,
-					"vulncheck":          false,
-				},
+				Version: clientVersion(),
 			},
+			RootPath:     roots[0],
+			RootURI:      protocol.DocumentUri("file://" + roots[0]),
+			Capabilities: clientCapabilities(),
 		},
 	}
+	if c.preset != nil && len(c.preset.InitializationOptions) > 0 {
+		initParams.InitializationOptions = c.preset.InitializationOptions
+	}
+
+	c.workspaceMu.Lock()
+	c.workspaceFolders = initParams.WorkspaceFolders
+	c.workspaceMu.Unlock()
+
+	// Registrations belong to the server process that made them
+	c.resetRegistrations()
+
+	// Register handlers before initializing, since servers may send requests
+	// as soon as they are initialized
+	c.registerHandlers()
 
 	var result protocol.InitializeResult
 	if err := c.Call(ctx, "initialize", initParams, &result); err != nil {
 		return nil, fmt.Errorf("initialize failed: %w", err)
 	}
 
+	c.serverCapabilitiesMu.Lock()
+	c.serverCapabilities = result.Capabilities
+	c.serverCapabilitiesMu.Unlock()
+	lspLogger.Info("Server uses %s positions", c.PositionEncoding())
+
 	if err := c.Initialized(ctx, protocol.InitializedParams{}); err != nil {
 		return nil, fmt.Errorf("initialized failed: %w", err)
 	}
+	c.initialized.Store(true)
 
-	// Register handlers
-	c.RegisterServerRequestHandler("workspace/applyEdit", HandleApplyEdit)
-	c.RegisterServerRequestHandler("workspace/configuration", HandleWorkspaceConfiguration)
-	c.RegisterServerRequestHandler("client/registerCapability", HandleRegisterCapability)
-	c.RegisterNotificationHandler("window/showMessage", HandleServerMessage)
-	c.RegisterNotificationHandler("textDocument/publishDiagnostics",
-		func(params json.RawMessage) { HandleDiagnostics(c, params) })
+	if err := c.pushSettings(ctx); err != nil {
+		return nil, fmt.Errorf("failed to send settings: %w", err)
+	}
 
-	// LSP sepecific Initialization
-	path := strings.ToLower(c.Cmd.Path)
-	switch {
-	case strings.Contains(path, "typescript-language-server"):
-		err := initializeTypescriptLanguageServer(ctx, c, workspaceDir)
-		if err != nil {
-			return nil, err
+	if c.preset != nil && len(c.preset.Preopen) > 0 {
+		for _, root := range roots {
+			if err := c.preopenFiles(ctx, root, c.preset.Preopen); err != nil {
+				return nil, fmt.Errorf("failed to open files: %w", err)
+			}
 		}
 	}
 
 	return &result, nil
 }
 
+// SetRoots sets the project roots the server is initialized with, for servers
+// of one project in a larger workspace. It must be called before the server
+// is initialized.
+func (c *Client) SetRoots(roots []string) {
+	c.workspaceMu.Lock()
+	defer c.workspaceMu.Unlock()
+	c.roots = roots
+}
+
+// rootDirs returns the project roots the server was given, or else the
+// workspace
+func (c *Client) rootDirs(workspaceDir string) []string {
+	c.workspaceMu.RLock()
+	defer c.workspaceMu.RUnlock()
+	if len(c.roots) == 0 {
+		return []string{workspaceDir}
+	}
+	return c.roots
+}
+
+// ServerCapabilities returns the capabilities the server reported during initialization
+func (c *Client) ServerCapabilities() protocol.ServerCapabilities {
+	c.serverCapabilitiesMu.RLock()
+	defer c.serverCapabilitiesMu.RUnlock()
+	return c.serverCapabilities
+}
+
+// WorkspaceFolders returns the workspace folders the server was initialized with
+func (c *Client) WorkspaceFolders() []protocol.WorkspaceFolder {
+	c.workspaceMu.RLock()
+	defer c.workspaceMu.RUnlock()
+	return c.workspaceFolders
+}
+
+// PositionEncoding returns the encoding the server counts position
+// characters in, UTF-16 unless it picked another during initialization
+func (c *Client) PositionEncoding() protocol.PositionEncodingKind {
+	if encoding := c.ServerCapabilities().PositionEncoding; encoding != nil && *encoding != "" {
+		return *encoding
+	}
+	return protocol.UTF16
+}
+
+// SupportsCodeActionResolve reports whether the server advertised
+// codeActionProvider.resolveProvider
+func (c *Client) SupportsCodeActionResolve() bool {
+	switch v := c.ServerCapabilities().CodeActionProvider.(type) {
+	case map[string]any:
+		resolve, _ := v["resolveProvider"].(bool)
+		return resolve
+	case protocol.CodeActionOptions:
+		return v.ResolveProvider
+	case *protocol.CodeActionOptions:
+		return v != nil && v.ResolveProvider
+	}
+	return false
+}
+
+// SupportsCompletionResolve reports whether the server advertised
+// completionProvider.resolveProvider
+func (c *Client) SupportsCompletionResolve() bool {
+	provider := c.ServerCapabilities().CompletionProvider
+	return provider != nil && provider.ResolveProvider
+}
+
+// SupportsPrepareRename reports whether the server advertised
+// renameProvider.prepareProvider
+func (c *Client) SupportsPrepareRename() bool {
+	switch v := c.ServerCapabilities().RenameProvider.(type) {
+	case map[string]any:
+		prepare, _ := v["prepareProvider"].(bool)
+		return prepare
+	case protocol.RenameOptions:
+		return v.PrepareProvider
+	case *protocol.RenameOptions:
+		return v != nil && v.PrepareProvider
+	}
+	return false
+}
+
+// SupportsPullDiagnostics reports whether the server advertised a
+// diagnosticProvider, so diagnostics can be requested with textDocument/diagnostic
+func (c *Client) SupportsPullDiagnostics() bool {
+	return c.ServerCapabilities().DiagnosticProvider != nil
+}
+
+// SupportsInlayHintResolve reports whether the server advertised
+// inlayHintProvider.resolveProvider
+func (c *Client) SupportsInlayHintResolve() bool {
+	switch v := c.ServerCapabilities().InlayHintProvider.(type) {
+	case map[string]any:
+		resolve, _ := v["resolveProvider"].(bool)
+		return resolve
+	case protocol.InlayHintOptions:
+		return v.ResolveProvider
+	case *protocol.InlayHintOptions:
+		return v != nil && v.ResolveProvider
+	}
+	return false
+}
+
 func (c *Client) Close() error {
+	c.closing.Store(true)
+
 	// Try to close all open files first
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -234,36 +455,38 @@ func (c *Client) Close() error {
 	// Attempt to close files but continue shutdown regardless
 	c.CloseAllFiles(ctx)
 
-	// Force kill the LSP process if it doesn't exit within timeout
-	forcedKill := make(chan struct{})
+	c.processMu.RLock()
+	conn, exited := c.conn, c.exited
+	c.processMu.RUnlock()
+
+	// Force kill the LSP process if it doesn't exit within timeout. Servers
+	// the client didn't start are only disconnected from.
+	stopKill := make(chan struct{})
 	go func() {
 		select {
 		case <-time.After(2 * time.Second):
-			lspLogger.Warn("LSP process did not exit within timeout, forcing kill")
-			if c.Cmd.Process != nil {
-				if err := c.Cmd.Process.Kill(); err != nil {
-					lspLogger.Error("Failed to kill process: %v", err)
-				} else {
-					lspLogger.Info("Process killed successfully")
-				}
+			lspLogger.Warn("LSP server %s did not exit within timeout, forcing kill", c.Name())
+			if err := conn.Kill(); err != nil {
+				lspLogger.Error("Failed to kill process: %v", err)
+			} else {
+				lspLogger.Info("Process killed successfully")
 			}
-			close(forcedKill)
-		case <-forcedKill:
-			// Channel closed from completion path
+		case <-stopKill:
+			// Process exited on its own
 			return
 		}
 	}()
 
-	// Close stdin to signal the server
-	if err := c.stdin.Close(); err != nil {
-		lspLogger.Error("Failed to close stdin: %v", err)
+	// Close stdin, or the connection, to signal the server
+	if err := conn.Close(); err != nil {
+		lspLogger.Error("Failed to close connection: %v", err)
 	}
 
 	// Wait for process to exit
-	err := c.Cmd.Wait()
-	close(forcedKill) // Stop the force kill goroutine
+	<-exited
+	close(stopKill) // Stop the force kill goroutine
 
-	return err
+	return c.ExitError()
 }
 
 type ServerState int
@@ -274,26 +497,32 @@ const (
 	StateError
 )
 
-func (c *Client) WaitForServerReady(ctx context.Context) error {
-	// TODO: wait for specific messages or poll workspace/symbol
-	time.Sleep(time.Second * 1)
-	return nil
-}
-
 type OpenFileInfo struct {
 	Version int32
 	URI     protocol.DocumentUri
+	// Text is the content last sent to the server, which incremental
+	// changes are computed against
+	Text string
+
+	// Value of the client's use clock when the file was last used
+	lastUsed uint64
+
+	// Serializes changes so they reach the server in version order
+	mu sync.Mutex
 }
 
 func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 	uri := fmt.Sprintf("file://%s", filepath)
 
-	c.openFilesMu.Lock()
-	if _, exists := c.openFiles[uri]; exists {
-		c.openFilesMu.Unlock()
+	if c.touchFile(uri) {
 		return nil // Already open
 	}
-	c.openFilesMu.Unlock()
+
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	if c.touchFile(uri) {
+		return nil // Opened while waiting
+	}
 
 	// Skip files that do not exist or cannot be read
 	content, err := os.ReadFile(filepath)
@@ -314,15 +543,20 @@ func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 		return err
 	}
 
-	c.openFilesMu.Lock()
-	c.openFiles[uri] = &OpenFileInfo{
+	info := &OpenFileInfo{
 		Version: 1,
 		URI:     protocol.DocumentUri(uri),
+		Text:    string(content),
 	}
+	c.openFilesMu.Lock()
+	c.touchLocked(info)
+	c.openFiles[uri] = info
 	c.openFilesMu.Unlock()
 
 	lspLogger.Debug("Opened file: %s", filepath)
 
+	c.evictFiles(ctx, uri)
+
 	return nil
 }
 
@@ -330,25 +564,81 @@ func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
 	uri := fmt.Sprintf("file://%s", filepath)
 
 	c.openFilesMu.Lock()
-	fileInfo, isOpen := c.openFiles[uri]
+	_, isOpen := c.openFiles[uri]
+	c.openFilesMu.Unlock()
 	if !isOpen {
-		c.openFilesMu.Unlock()
 		lspLogger.Debug("NotifyChange: skipping unopened file %s", filepath)
 		return nil
 	}
-	c.openFilesMu.Unlock()
 
 	content, err := os.ReadFile(filepath)
 	if err != nil {
 		return fmt.Errorf("error reading file: %w", err)
 	}
 
+	if err := c.NotifyContent(ctx, filepath, string(content)); err != nil {
+		return err
+	}
+
+	// The new content is on disk, so it has been saved. Servers such as
+	// rust-analyzer only run some checks on save.
+	send, includeText := c.saveOptions()
+	if !send {
+		return nil
+	}
+	params := protocol.DidSaveTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri(uri)},
+	}
+	if includeText {
+		text := string(content)
+		params.Text = &text
+	}
+	return c.DidSave(ctx, params)
+}
+
+// NotifyContent tells the server that an open file now contains content,
+// without writing it to disk. Calling NotifyChange afterwards restores the
+// server's view of the file to what is on disk.
+func (c *Client) NotifyContent(ctx context.Context, filepath string, content string) error {
+	uri := fmt.Sprintf("file://%s", filepath)
+
+	// The server's view of the file differs from the disk until the change
+	// is undone, so it mustn't be evicted in the meantime
+	defer c.pinURI(uri)()
+
 	c.openFilesMu.Lock()
+	fileInfo, isOpen := c.openFiles[uri]
+	if isOpen {
+		c.touchLocked(fileInfo)
+	}
+	c.openFilesMu.Unlock()
+	if !isOpen {
+		return fmt.Errorf("file not open: %s", filepath)
+	}
+
+	// Hold the file's lock until the change is sent so that changes reach
+	// the server in version order, each computed against the text before it
+	fileInfo.mu.Lock()
+	defer fileInfo.mu.Unlock()
+
+	// Send only the changed range when the server accepts incremental
+	// changes, and the whole document otherwise
+	change := protocol.TextDocumentContentChangeEvent{
+		Value: protocol.TextDocumentContentChangeWholeDocument{
+			Text: content,
+		},
+	}
+	if c.textDocumentSyncKind() == protocol.Incremental {
+		partial, changed := incrementalChange(fileInfo.Text, content, c.PositionEncoding())
+		if !changed {
+			return nil
+		}
+		change.Value = partial
+	}
 
 	// Increment version
 	fileInfo.Version++
 	version := fileInfo.Version
-	c.openFilesMu.Unlock()
 
 	params := protocol.DidChangeTextDocumentParams{
 		TextDocument: protocol.VersionedTextDocumentIdentifier{
@@ -357,21 +647,22 @@ func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
 			},
 			Version: version,
 		},
-		ContentChanges: []protocol.TextDocumentContentChangeEvent{
-			{
-				Value: protocol.TextDocumentContentChangeWholeDocument{
-					Text: string(content),
-				},
-			},
-		},
+		ContentChanges: []protocol.TextDocumentContentChangeEvent{change},
 	}
 
-	return c.Notify(ctx, "textDocument/didChange", params)
+	if err := c.Notify(ctx, "textDocument/didChange", params); err != nil {
+		return err
+	}
+	fileInfo.Text = content
+	return nil
 }
 
 func (c *Client) CloseFile(ctx context.Context, filepath string) error {
 	uri := fmt.Sprintf("file://%s", filepath)
 
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
 	c.openFilesMu.Lock()
 	if _, exists := c.openFiles[uri]; !exists {
 		c.openFilesMu.Unlock()
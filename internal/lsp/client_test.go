@@ -0,0 +1,92 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func TestSupportsCodeActionResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		expected bool
+	}{
+		{name: "options with resolve", provider: `{"resolveProvider": true}`, expected: true},
+		{name: "options without resolve", provider: `{"codeActionKinds": ["quickfix"]}`, expected: false},
+		{name: "boolean", provider: `true`, expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var caps protocol.ServerCapabilities
+			if err := json.Unmarshal([]byte(`{"codeActionProvider": `+tc.provider+`}`), &caps); err != nil {
+				t.Fatalf("failed to decode capabilities: %v", err)
+			}
+
+			client := &Client{serverCapabilities: caps}
+			if got := client.SupportsCodeActionResolve(); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPositionEncoding(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		expected     protocol.PositionEncodingKind
+	}{
+		{name: "utf-8", capabilities: `{"positionEncoding": "utf-8"}`, expected: protocol.UTF8},
+		{name: "utf-32", capabilities: `{"positionEncoding": "utf-32"}`, expected: protocol.UTF32},
+		{name: "not reported", capabilities: `{}`, expected: protocol.UTF16},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var caps protocol.ServerCapabilities
+			if err := json.Unmarshal([]byte(tc.capabilities), &caps); err != nil {
+				t.Fatalf("failed to decode capabilities: %v", err)
+			}
+
+			client := &Client{serverCapabilities: caps}
+			if got := client.PositionEncoding(); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestInitializeLSPClient_Roots(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	transport := pipeTransport{initialized: make(chan protocol.InitializeParams, 1)}
+	client, err := NewClientWithTransport(transport)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	client.SetRoots([]string{"/repo/backend", "/repo/tools"})
+	if _, err := client.InitializeLSPClient(ctx, "/repo"); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	params := <-transport.initialized
+	if params.RootURI != "file:///repo/backend" {
+		t.Errorf("expected the first root as rootUri, got %s", params.RootURI)
+	}
+	var folders []string
+	for _, folder := range params.WorkspaceFolders {
+		folders = append(folders, string(folder.URI))
+	}
+	if want := []string{"file:///repo/backend", "file:///repo/tools"}; !reflect.DeepEqual(folders, want) {
+		t.Errorf("workspaceFolders = %v, want %v", folders, want)
+	}
+}
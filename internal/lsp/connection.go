@@ -0,0 +1,175 @@
+package lsp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// dialTimeout bounds how long connecting to a running server may take
+const dialTimeout = 10 * time.Second
+
+// Transport connects a client to its language server, either by starting the
+// server or by connecting to one that is already running
+type Transport interface {
+	// Connect starts or dials the server. It is called again to reconnect
+	// after the server exits.
+	Connect() (Conn, error)
+	// Owned reports whether the transport starts the server itself. Only
+	// servers the client started are shut down and killed by it; others
+	// may be shared with editors and are only disconnected from.
+	Owned() bool
+	// String describes the server for messages about it
+	String() string
+}
+
+// Conn is an open connection to a language server. Messages are written to
+// it and read from it with WriteMessage and ReadMessage.
+type Conn interface {
+	io.ReadWriter
+	// Close ends the client's side of the connection, which asks a server
+	// process to exit
+	Close() error
+	// Wait waits for the server to go away once its output has ended, and
+	// returns the error it exited with
+	Wait() error
+	// Kill stops a server process that doesn't exit on its own. Servers the
+	// client didn't start are disconnected from instead.
+	Kill() error
+}
+
+// ProcessTransport starts the language server as a child process and talks
+// to it over its stdin and stdout
+type ProcessTransport struct {
+	Command string
+	Args    []string
+}
+
+func (t ProcessTransport) Connect() (Conn, error) {
+	cmd := exec.Command(t.Command, t.Args...)
+	// Copy env
+	cmd.Env = os.Environ()
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	// Start the LSP server process
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start LSP server: %w", err)
+	}
+
+	// Handle stderr in a separate goroutine with proper logging
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			line := scanner.Text()
+			processLogger.Info("%s", line)
+		}
+		if err := scanner.Err(); err != nil {
+			lspLogger.Error("Error reading LSP server stderr: %v", err)
+		}
+	}()
+
+	return &processConn{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+}
+
+func (t ProcessTransport) Owned() bool {
+	return true
+}
+
+func (t ProcessTransport) String() string {
+	return filepath.Base(t.Command)
+}
+
+// processConn is the stdin and stdout of a server process
+type processConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func (p *processConn) Read(b []byte) (int, error) {
+	return p.stdout.Read(b)
+}
+
+func (p *processConn) Write(b []byte) (int, error) {
+	return p.stdin.Write(b)
+}
+
+// Close closes stdin, which servers take as a sign to exit
+func (p *processConn) Close() error {
+	return p.stdin.Close()
+}
+
+func (p *processConn) Wait() error {
+	return p.cmd.Wait()
+}
+
+func (p *processConn) Kill() error {
+	if p.cmd.Process == nil {
+		return nil
+	}
+	return p.cmd.Process.Kill()
+}
+
+// SocketTransport connects to a language server that is already running and
+// listening on a Unix socket or a TCP address, such as gopls started with
+// -listen or a clangd shared with an editor
+type SocketTransport struct {
+	// "unix" or "tcp"
+	Network string
+	Address string
+}
+
+func (t SocketTransport) Connect() (Conn, error) {
+	conn, err := net.DialTimeout(t.Network, t.Address, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LSP server: %w", err)
+	}
+	return &socketConn{Conn: conn}, nil
+}
+
+func (t SocketTransport) Owned() bool {
+	return false
+}
+
+func (t SocketTransport) String() string {
+	return t.Network + ":" + t.Address
+}
+
+// socketConn is a connection to a server the client didn't start. Closing it
+// leaves the server running.
+type socketConn struct {
+	net.Conn
+}
+
+// Wait returns once the connection is closed; the server keeps running
+func (s *socketConn) Wait() error {
+	return nil
+}
+
+// Kill disconnects from the server without stopping it
+func (s *socketConn) Kill() error {
+	if err := s.Conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
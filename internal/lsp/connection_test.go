@@ -0,0 +1,145 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// serveFakeServer acts as a language server that is already running, serving
+// each client that connects to listener with serveFakeConn
+func serveFakeServer(listener net.Listener, initialized chan<- protocol.InitializeParams, disconnected chan<- struct{}) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go serveFakeConn(conn, initialized, disconnected)
+	}
+}
+
+// serveFakeConn answers a client's requests, sending the params it
+// initializes with on initialized, and signals on disconnected when the
+// client goes away
+func serveFakeConn(conn net.Conn, initialized chan<- protocol.InitializeParams, disconnected chan<- struct{}) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		msg, err := ReadMessage(reader)
+		if err != nil {
+			disconnected <- struct{}{}
+			return
+		}
+		if msg.ID == nil {
+			continue
+		}
+
+		response := &Message{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage(`null`)}
+		if msg.Method == "initialize" {
+			var params protocol.InitializeParams
+			_ = json.Unmarshal(msg.Params, &params)
+			initialized <- params
+			response.Result = initializeResult(msg.Params)
+		}
+		if err := WriteMessage(conn, response); err != nil {
+			return
+		}
+	}
+}
+
+// pipeTransport connects a client to serveFakeConn over an in-memory pipe
+type pipeTransport struct {
+	initialized chan protocol.InitializeParams
+}
+
+func (t pipeTransport) Connect() (Conn, error) {
+	client, server := net.Pipe()
+	go serveFakeConn(server, t.initialized, make(chan struct{}, 1))
+	return &socketConn{Conn: client}, nil
+}
+
+func (t pipeTransport) Owned() bool    { return false }
+func (t pipeTransport) String() string { return "pipe" }
+
+func TestSocketTransport_ConnectsToRunningServer(t *testing.T) {
+	// Unix socket paths are limited to about 100 bytes, which test temp dirs
+	// can exceed
+	socketDir, err := os.MkdirTemp("", "lsp")
+	if err != nil {
+		t.Fatalf("failed to create socket dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+
+	tests := []struct {
+		network string
+		address string
+	}{
+		{"unix", filepath.Join(socketDir, "server.sock")},
+		{"tcp", "127.0.0.1:0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			listener, err := net.Listen(tt.network, tt.address)
+			if err != nil {
+				t.Fatalf("failed to listen: %v", err)
+			}
+			defer listener.Close()
+
+			initialized := make(chan protocol.InitializeParams, 2)
+			disconnected := make(chan struct{}, 2)
+			go serveFakeServer(listener, initialized, disconnected)
+
+			transport := SocketTransport{Network: tt.network, Address: listener.Addr().String()}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// Two clients in turn, as when the MCP server is restarted
+			for i := range 2 {
+				client, err := NewClientWithTransport(transport)
+				if err != nil {
+					t.Fatalf("client %d failed to connect: %v", i, err)
+				}
+				if client.OwnsServer() {
+					t.Errorf("client %d should not own a server it connected to", i)
+				}
+
+				if _, err := client.InitializeLSPClient(ctx, t.TempDir()); err != nil {
+					t.Fatalf("client %d failed to initialize: %v", i, err)
+				}
+				if got := (<-initialized).ProcessID; got != 0 {
+					t.Errorf("client %d sent process ID %d; the server would exit with us", i, got)
+				}
+
+				if err := client.Close(); err != nil {
+					t.Errorf("client %d failed to close: %v", i, err)
+				}
+				select {
+				case <-disconnected:
+				case <-ctx.Done():
+					t.Fatalf("client %d did not disconnect when closed", i)
+				}
+			}
+		})
+	}
+}
+
+func TestSocketTransport_ConnectFails(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	if _, err := NewClientWithTransport(SocketTransport{Network: "tcp", Address: address}); err == nil {
+		t.Error("expected an error connecting to an address nothing listens on")
+	}
+}
@@ -67,6 +67,31 @@ func (c *Client) WaitForDiagnostics(ctx context.Context, uri protocol.DocumentUr
 	}
 }
 
+// PullDiagnostics requests the diagnostics for uri with textDocument/diagnostic
+// and caches them alongside published diagnostics. Only servers for which
+// SupportsPullDiagnostics is true answer it.
+func (c *Client) PullDiagnostics(ctx context.Context, uri protocol.DocumentUri) ([]protocol.Diagnostic, error) {
+	report, err := c.Diagnostic(ctx, protocol.DocumentDiagnosticParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := report.Value.(type) {
+	case protocol.RelatedFullDocumentDiagnosticReport:
+		c.diagnosticsMu.Lock()
+		c.diagnostics[uri] = v.Items
+		c.diagnosticsMu.Unlock()
+		diagLogger.Debug("PullDiagnostics: %d diagnostics for %s", len(v.Items), uri)
+		return v.Items, nil
+	default:
+		// No previous result ID is sent, so the report should always be
+		// full, but an unchanged report means the cached diagnostics hold
+		return c.GetFileDiagnostics(uri), nil
+	}
+}
+
 // settleDiagnostics waits for additional diagnostic notifications to stop
 // arriving. Each new notification resets the settle timer. Returns when
 // no new notifications arrive within diagnosticSettleTime or the context
@@ -0,0 +1,216 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// DefaultMaxOpenFiles is the number of documents a client keeps open on the
+// server before closing the least recently used ones. Every open document
+// costs the server memory, and some servers keep a file descriptor per
+// document.
+const DefaultMaxOpenFiles = 1000
+
+// OpenFileStats describes the documents the client has open on the server
+type OpenFileStats struct {
+	// Documents currently open
+	Open int
+	// Limit on open documents, 0 if there is none
+	Max int
+	// Open documents that can't be evicted because operations on them are pending
+	Pinned int
+	// Documents closed to stay within the limit
+	Evictions uint64
+	// Times the limit was exceeded because every document was pinned
+	Overflows uint64
+}
+
+// SetMaxOpenFiles limits the number of documents kept open on the server,
+// closing the least recently used ones beyond it. 0 removes the limit.
+func (c *Client) SetMaxOpenFiles(ctx context.Context, limit int) {
+	c.openFilesMu.Lock()
+	c.maxOpenFiles = limit
+	c.openFilesMu.Unlock()
+
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	c.evictFiles(ctx, "")
+}
+
+// OpenFileStats returns the number of open documents and eviction counts
+func (c *Client) OpenFileStats() OpenFileStats {
+	c.openFilesMu.RLock()
+	defer c.openFilesMu.RUnlock()
+
+	pinned := 0
+	for uri := range c.openFiles {
+		if c.pins[uri] > 0 {
+			pinned++
+		}
+	}
+	return OpenFileStats{
+		Open:      len(c.openFiles),
+		Max:       c.maxOpenFiles,
+		Pinned:    pinned,
+		Evictions: c.evictions.Load(),
+		Overflows: c.overflows.Load(),
+	}
+}
+
+// OpenStartupFile opens a file that should be open from the start, such as
+// one matching a preset's preopen globs, and remembers it so that StartupFile
+// can tell it from files opened for a request
+func (c *Client) OpenStartupFile(ctx context.Context, filepath string) error {
+	if err := c.OpenFile(ctx, filepath); err != nil {
+		return err
+	}
+
+	c.openFilesMu.Lock()
+	defer c.openFilesMu.Unlock()
+	if c.startupFiles == nil {
+		c.startupFiles = make(map[string]struct{})
+	}
+	c.startupFiles[filepath] = struct{}{}
+	return nil
+}
+
+// StartupFile reports whether filepath was opened with OpenStartupFile
+func (c *Client) StartupFile(filepath string) bool {
+	c.openFilesMu.RLock()
+	defer c.openFilesMu.RUnlock()
+	_, ok := c.startupFiles[filepath]
+	return ok
+}
+
+// PinFile keeps filepath open until the returned function is called, for
+// operations that span several requests. Pins are counted, and may be taken
+// before the file is opened.
+func (c *Client) PinFile(filepath string) (unpin func()) {
+	return c.pinURI(fmt.Sprintf("file://%s", filepath))
+}
+
+// pinURI stops uri being evicted until the returned function is called
+func (c *Client) pinURI(uri string) func() {
+	c.openFilesMu.Lock()
+	if c.pins == nil {
+		c.pins = make(map[string]int)
+	}
+	c.pins[uri]++
+	c.openFilesMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.openFilesMu.Lock()
+			defer c.openFilesMu.Unlock()
+			c.pins[uri]--
+			if c.pins[uri] <= 0 {
+				delete(c.pins, uri)
+			}
+		})
+	}
+}
+
+// touchFile marks uri as the most recently used document, returning false
+// if it isn't open
+func (c *Client) touchFile(uri string) bool {
+	c.openFilesMu.Lock()
+	defer c.openFilesMu.Unlock()
+
+	info, ok := c.openFiles[uri]
+	if ok {
+		c.touchLocked(info)
+	}
+	return ok
+}
+
+// touchLocked marks info as the most recently used document. openFilesMu
+// must be held.
+func (c *Client) touchLocked(info *OpenFileInfo) {
+	c.useClock++
+	info.lastUsed = c.useClock
+}
+
+// evictFiles closes the least recently used documents that aren't pinned
+// until no more than the limit are open. keep is never evicted. syncMu must
+// be held, so that a document being evicted isn't reopened before its
+// didClose is sent.
+func (c *Client) evictFiles(ctx context.Context, keep string) {
+	for {
+		c.openFilesMu.Lock()
+		if c.maxOpenFiles <= 0 || len(c.openFiles) <= c.maxOpenFiles {
+			c.openFilesMu.Unlock()
+			return
+		}
+
+		var victim *OpenFileInfo
+		for uri, info := range c.openFiles {
+			if uri == keep || c.pins[uri] > 0 {
+				continue
+			}
+			if victim == nil || info.lastUsed < victim.lastUsed {
+				victim = info
+			}
+		}
+		if victim == nil {
+			open := len(c.openFiles)
+			c.openFilesMu.Unlock()
+			c.overflows.Add(1)
+			lspLogger.Warn("%d files are open, more than the limit of %d, because all of them have pending operations", open, c.maxOpenFiles)
+			return
+		}
+		// Forget the document before closing it so that requests using
+		// it reopen it once the close has been sent
+		delete(c.openFiles, string(victim.URI))
+		c.openFilesMu.Unlock()
+
+		params := protocol.DidCloseTextDocumentParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: victim.URI},
+		}
+		if err := c.Notify(ctx, "textDocument/didClose", params); err != nil {
+			lspLogger.Error("Failed to close evicted file %s: %v", victim.URI, err)
+			return
+		}
+		count := c.evictions.Add(1)
+		lspLogger.Debug("Evicted least recently used file %s (%d evictions so far)", victim.URI, count)
+	}
+}
+
+// openFilePaths returns the paths of the open documents, least recently used
+// first. openFilesMu must be held.
+func (c *Client) openFilePaths() []string {
+	infos := make([]*OpenFileInfo, 0, len(c.openFiles))
+	for _, info := range c.openFiles {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].lastUsed < infos[j].lastUsed
+	})
+
+	paths := make([]string, len(infos))
+	for i, info := range infos {
+		paths[i] = strings.TrimPrefix(string(info.URI), "file://")
+	}
+	return paths
+}
+
+// requestDocument returns the URI of the document a request is about, if its
+// params have a textDocument, so the document can be pinned while the request
+// is pending
+func requestDocument(params json.RawMessage) string {
+	var doc struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal(params, &doc); err != nil {
+		return ""
+	}
+	return doc.TextDocument.URI
+}
@@ -0,0 +1,194 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newOpenFilesClient returns a client that writes to a pipe, and a channel of
+// the URIs of the documents it sends didClose for
+func newOpenFilesClient(t *testing.T, limit int) (*Client, <-chan string) {
+	t.Helper()
+	reader, writer := io.Pipe()
+	t.Cleanup(func() { _ = writer.Close() })
+
+	client := &Client{
+		writer:       writer,
+		openFiles:    make(map[string]*OpenFileInfo),
+		maxOpenFiles: limit,
+	}
+
+	closed := make(chan string, 10)
+	go func() {
+		r := bufio.NewReader(reader)
+		for {
+			msg, err := ReadMessage(r)
+			if err != nil {
+				return
+			}
+			if msg.Method != "textDocument/didClose" {
+				continue
+			}
+			var params struct {
+				TextDocument struct {
+					URI string `json:"uri"`
+				} `json:"textDocument"`
+			}
+			if err := json.Unmarshal(msg.Params, &params); err == nil {
+				closed <- params.TextDocument.URI
+			}
+		}
+	}()
+	return client, closed
+}
+
+// writeFiles creates an empty file for each name and returns their paths
+func writeFiles(t *testing.T, names ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+		if err := os.WriteFile(paths[i], []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func expectClosed(t *testing.T, closed <-chan string, path string) {
+	t.Helper()
+	if got := <-closed; got != "file://"+path {
+		t.Errorf("expected %s to be closed, got %s", path, got)
+	}
+}
+
+func TestOpenFile_EvictsLeastRecentlyUsed(t *testing.T) {
+	client, closed := newOpenFilesClient(t, 2)
+	paths := writeFiles(t, "a.go", "b.go", "c.go", "d.go")
+	ctx := context.Background()
+
+	for _, path := range paths[:3] {
+		if err := client.OpenFile(ctx, path); err != nil {
+			t.Fatalf("OpenFile(%s) failed: %v", path, err)
+		}
+	}
+	expectClosed(t, closed, paths[0])
+
+	// Using b makes c the least recently used
+	if err := client.OpenFile(ctx, paths[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.OpenFile(ctx, paths[3]); err != nil {
+		t.Fatal(err)
+	}
+	expectClosed(t, closed, paths[2])
+
+	if client.IsFileOpen(paths[0]) || client.IsFileOpen(paths[2]) {
+		t.Error("expected evicted files to be closed")
+	}
+	if !client.IsFileOpen(paths[1]) || !client.IsFileOpen(paths[3]) {
+		t.Error("expected recently used files to stay open")
+	}
+
+	stats := client.OpenFileStats()
+	if stats.Open != 2 || stats.Max != 2 || stats.Evictions != 2 || stats.Overflows != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestOpenStartupFile(t *testing.T) {
+	client, _ := newOpenFilesClient(t, 0)
+	paths := writeFiles(t, "startup.go", "request.go")
+	ctx := context.Background()
+
+	if err := client.OpenStartupFile(ctx, paths[0]); err != nil {
+		t.Fatalf("OpenStartupFile failed: %v", err)
+	}
+	if err := client.OpenFile(ctx, paths[1]); err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+
+	if !client.IsFileOpen(paths[0]) || !client.StartupFile(paths[0]) {
+		t.Errorf("expected %s to be open as a startup file", paths[0])
+	}
+	if !client.IsFileOpen(paths[1]) || client.StartupFile(paths[1]) {
+		t.Errorf("expected %s to be open but not as a startup file", paths[1])
+	}
+}
+
+func TestOpenFile_KeepsPinnedFiles(t *testing.T) {
+	client, closed := newOpenFilesClient(t, 1)
+	paths := writeFiles(t, "a.go", "b.go", "c.go")
+	ctx := context.Background()
+
+	unpin := client.PinFile(paths[0])
+	if err := client.OpenFile(ctx, paths[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// a is pinned, so the limit is exceeded rather than closing it
+	if err := client.OpenFile(ctx, paths[1]); err != nil {
+		t.Fatal(err)
+	}
+	if !client.IsFileOpen(paths[0]) || !client.IsFileOpen(paths[1]) {
+		t.Fatal("expected both files to stay open")
+	}
+	stats := client.OpenFileStats()
+	if stats.Pinned != 1 || stats.Overflows != 1 || stats.Evictions != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// Once unpinned, a is the least recently used
+	unpin()
+	unpin() // unpinning twice is harmless
+	if err := client.OpenFile(ctx, paths[2]); err != nil {
+		t.Fatal(err)
+	}
+	expectClosed(t, closed, paths[0])
+	expectClosed(t, closed, paths[1])
+	if !client.IsFileOpen(paths[2]) {
+		t.Error("expected the file just opened to stay open")
+	}
+}
+
+func TestSetMaxOpenFiles(t *testing.T) {
+	client, closed := newOpenFilesClient(t, 0)
+	paths := writeFiles(t, "a.go", "b.go", "c.go")
+	ctx := context.Background()
+
+	for _, path := range paths {
+		if err := client.OpenFile(ctx, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := client.OpenFileStats(); stats.Open != 3 {
+		t.Fatalf("expected no limit to keep 3 files open, got %+v", stats)
+	}
+
+	client.SetMaxOpenFiles(ctx, 1)
+	expectClosed(t, closed, paths[0])
+	expectClosed(t, closed, paths[1])
+	if stats := client.OpenFileStats(); stats.Open != 1 || stats.Evictions != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRequestDocument(t *testing.T) {
+	tests := map[string]string{
+		`{"textDocument":{"uri":"file:///a.go"},"position":{"line":0,"character":0}}`: "file:///a.go",
+		`{"query":"main"}`: "",
+		`null`:             "",
+		`[1,2]`:            "",
+	}
+	for params, expected := range tests {
+		if got := requestDocument(json.RawMessage(params)); got != expected {
+			t.Errorf("requestDocument(%s) = %q, want %q", params, got, expected)
+		}
+	}
+}
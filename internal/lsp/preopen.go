@@ -0,0 +1,73 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// preopenFiles opens the files in the workspace matching the preset's preopen
+// globs, for servers that only know about the projects of open files
+func (c *Client) preopenFiles(ctx context.Context, workspaceDir string, globs []string) error {
+	lspLogger.Info("Opening files matching %s in workspace: %s", strings.Join(globs, ", "), workspaceDir)
+
+	// Track count of opened files for logging
+	fileCount := 0
+
+	// Walk the workspace directory
+	err := filepath.WalkDir(workspaceDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip node_modules, .git, and other common directories to avoid processing too many files
+		if d.IsDir() {
+			basename := d.Name()
+			if path != workspaceDir && (basename == "node_modules" || strings.HasPrefix(basename, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(workspaceDir, path)
+		if err != nil {
+			return nil
+		}
+		if !matchesAny(globs, filepath.ToSlash(rel)) {
+			return nil
+		}
+
+		// Opening more would only evict the files opened first
+		if limit := c.OpenFileStats().Max; limit > 0 && fileCount >= limit {
+			lspLogger.Info("Reached the limit of %d open files, not opening the remaining files", limit)
+			return filepath.SkipAll
+		}
+		if err := c.OpenStartupFile(ctx, path); err != nil {
+			lspLogger.Warn("Failed to open file %s: %v", path, err)
+			return nil // Continue with other files even if one fails
+		}
+		fileCount++
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("error walking workspace directory: %w", err)
+	}
+
+	lspLogger.Info("Opened %d files", fileCount)
+	return nil
+}
+
+// matchesAny reports whether the slash-separated path matches any of globs
+func matchesAny(globs []string, path string) bool {
+	for _, glob := range globs {
+		if match, _ := doublestar.Match(glob, path); match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package lsp
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PresetsFileName is the presets file looked for in the user's config
+// directory when no presets file is given explicitly
+const PresetsFileName = "presets.yaml"
+
+//go:embed presets.yaml
+var builtinPresetsFile []byte
+
+// Preset describes how to detect, start and set up a language server
+type Preset struct {
+	Name string `json:"name"`
+	// Files that make the directory they are in a root of the server's project
+	Markers []string `json:"markers"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	// Language IDs and file extensions the server handles, as in ServerConfig
+	Languages []string `json:"languages"`
+	// Sent as initializationOptions in the initialize request
+	InitializationOptions map[string]any `json:"initializationOptions"`
+	// Settings the settings file is merged over, nested or dotted as in it
+	Settings map[string]any `json:"settings"`
+	// Globs, relative to the workspace, of files to open once the server
+	// is initialized
+	Preopen   []string  `json:"preopen"`
+	Readiness Readiness `json:"readiness"`
+}
+
+// Readiness tunes how long to wait for a server to be ready after it starts.
+// Zero values keep the defaults.
+type Readiness struct {
+	// How long the server may take to start reporting progress before it is
+	// assumed to have no work to do
+	ProgressGrace Duration `json:"progressGrace"`
+	// How long to wait for the reported work, such as indexing, to finish
+	Timeout Duration `json:"timeout"`
+}
+
+// Duration is a time.Duration written as a string such as "30s" in presets
+// files
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Presets is an ordered list of presets. Earlier presets come first among
+// the projects detected in a directory, and their servers are asked first
+// about files that several servers handle.
+type Presets []Preset
+
+// BuiltinPresets returns the presets that ship with the server
+func BuiltinPresets() Presets {
+	presets, err := parsePresets(builtinPresetsFile, ".yaml")
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in presets: %v", err))
+	}
+	return presets
+}
+
+// LoadPresets reads a presets file, YAML or JSON by its extension, and returns
+// the built-in presets with the file's presets merged in: a preset replaces
+// the built-in one of the same name, and new presets come before the
+// built-in ones.
+func LoadPresets(path string) (Presets, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := parsePresets(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid presets file %s: %w", path, err)
+	}
+
+	builtin := BuiltinPresets()
+	var added Presets
+	for _, preset := range overrides {
+		if i := builtin.index(preset.Name); i >= 0 {
+			builtin[i] = preset
+		} else {
+			added = append(added, preset)
+		}
+	}
+	return append(added, builtin...), nil
+}
+
+// DefaultPresetsPath returns the presets file in the user's config directory,
+// or "" if there is none
+func DefaultPresetsPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range []string{PresetsFileName, "presets.yml", "presets.json"} {
+		path := filepath.Join(configDir, "mcp-language-server", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// parsePresets parses a presets file. YAML is converted to JSON first so that
+// both formats are decoded with the same field names and checks.
+func parsePresets(data []byte, ext string) (Presets, error) {
+	if ext == ".yaml" || ext == ".yml" {
+		var document any
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		converted, err := json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	}
+
+	var file struct {
+		Presets Presets `json:"presets"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for i, preset := range file.Presets {
+		if preset.Name == "" {
+			return nil, fmt.Errorf("preset %d has no name", i+1)
+		}
+		if preset.Command == "" {
+			return nil, fmt.Errorf("preset %s has no command", preset.Name)
+		}
+		for j, language := range preset.Languages {
+			file.Presets[i].Languages[j] = strings.ToLower(language)
+		}
+	}
+	return file.Presets, nil
+}
+
+// Find returns the preset with the given name, or nil
+func (p Presets) Find(name string) *Preset {
+	if i := p.index(name); i >= 0 {
+		return &p[i]
+	}
+	return nil
+}
+
+// ForCommand returns the preset that starts command, matched by the name of
+// the executable, or nil
+func (p Presets) ForCommand(command string) *Preset {
+	base := filepath.Base(command)
+	for i := range p {
+		if filepath.Base(p[i].Command) == base {
+			return &p[i]
+		}
+	}
+	return nil
+}
+
+func (p Presets) index(name string) int {
+	for i := range p {
+		if p[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ServerConfig returns the configuration for starting the preset's server
+func (p *Preset) ServerConfig() ServerConfig {
+	return ServerConfig{Command: p.Command, Args: p.Args, Languages: p.Languages, Preset: p}
+}
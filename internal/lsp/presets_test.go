@@ -0,0 +1,155 @@
+package lsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func TestBuiltinPresets(t *testing.T) {
+	presets := BuiltinPresets()
+
+	gopls := presets.Find("gopls")
+	if gopls == nil {
+		t.Fatal("expected a gopls preset")
+	}
+	if _, ok := gopls.InitializationOptions["codelenses"]; !ok {
+		t.Errorf("expected gopls to enable code lenses, got %#v", gopls.InitializationOptions)
+	}
+	for _, preset := range presets {
+		if preset.Name != "gopls" && preset.InitializationOptions != nil {
+			t.Errorf("expected only gopls to have initialization options, %s has %#v", preset.Name, preset.InitializationOptions)
+		}
+	}
+
+	if got := presets.ForCommand("/usr/local/bin/pyright-langserver"); got == nil || got.Name != "pyright" {
+		t.Errorf("ForCommand(pyright-langserver) = %v, want the pyright preset", got)
+	}
+	if got := presets.ForCommand("unknown-server"); got != nil {
+		t.Errorf("ForCommand(unknown-server) = %v, want nil", got)
+	}
+}
+
+func TestLoadPresets(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "presets.yaml")
+	content := `presets:
+  - name: jdtls
+    markers: [pom.xml, build.gradle]
+    command: jdtls
+    languages: [Java]
+    readiness:
+      timeout: 2m
+  - name: gopls
+    markers: [go.work]
+    command: gopls
+    args: [-remote=auto]
+`
+	if err := os.WriteFile(yamlPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write presets: %v", err)
+	}
+
+	presets, err := LoadPresets(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadPresets failed: %v", err)
+	}
+
+	// New presets come first, so they win over the built-in ones
+	if presets[0].Name != "jdtls" {
+		t.Errorf("expected the new preset first, got %s", presets[0].Name)
+	}
+	jdtls := presets[0]
+	if !reflect.DeepEqual(jdtls.Languages, []string{"java"}) {
+		t.Errorf("expected lowercased languages, got %v", jdtls.Languages)
+	}
+	if time.Duration(jdtls.Readiness.Timeout) != 2*time.Minute {
+		t.Errorf("expected a 2m readiness timeout, got %v", time.Duration(jdtls.Readiness.Timeout))
+	}
+
+	// A preset with a built-in name replaces it in place
+	gopls := presets.Find("gopls")
+	if gopls == nil || !reflect.DeepEqual(gopls.Args, []string{"-remote=auto"}) || gopls.InitializationOptions != nil {
+		t.Errorf("expected the file's gopls preset to replace the built-in one, got %+v", gopls)
+	}
+	if len(presets) != len(BuiltinPresets())+1 {
+		t.Errorf("expected %d presets, got %d", len(BuiltinPresets())+1, len(presets))
+	}
+
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "pom.xml"), nil, 0644); err != nil {
+		t.Fatalf("failed to write marker: %v", err)
+	}
+	if roots, err := presets.DetectRoots(workspace, 0, nil); err != nil || len(roots) != 1 || roots[0].Preset.Name != "jdtls" {
+		t.Errorf("DetectRoots() = %v, %v; want jdtls", roots, err)
+	}
+}
+
+func TestLoadPresets_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.json")
+	content := `{"presets": [{"name": "zls", "markers": ["build.zig"], "command": "zls", "languages": [".zig"]}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write presets: %v", err)
+	}
+
+	presets, err := LoadPresets(path)
+	if err != nil {
+		t.Fatalf("LoadPresets failed: %v", err)
+	}
+	if zls := presets.Find("zls"); zls == nil || zls.Command != "zls" {
+		t.Errorf("expected a zls preset, got %+v", zls)
+	}
+}
+
+func TestLoadPresets_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"presets.yaml": "presets:\n  - name: solargraph\n",
+		"presets.json": `{"presets": [{"command": "solargraph"}]}`,
+		"bad.json":     `{"presets": [{"name": "x", "command": "x", "readiness": {"timeout": 5}}]}`,
+	}
+	for name, content := range tests {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write presets: %v", err)
+		}
+		if _, err := LoadPresets(path); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+	}
+}
+
+func TestInitializeLSPClient_PresetInitializationOptions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	presets := BuiltinPresets()
+	tests := []struct {
+		preset      string
+		wantOptions bool
+	}{
+		{"gopls", true},
+		{"pyright", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		transport := pipeTransport{initialized: make(chan protocol.InitializeParams, 1)}
+		client, err := NewClientWithTransport(transport)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		client.SetPreset(presets.Find(tt.preset))
+		if _, err := client.InitializeLSPClient(ctx, t.TempDir()); err != nil {
+			t.Fatalf("initialize failed: %v", err)
+		}
+
+		params := <-transport.initialized
+		if got := params.InitializationOptions != nil; got != tt.wantOptions {
+			t.Errorf("preset %q: initializationOptions = %#v, want them sent: %v", tt.preset, params.InitializationOptions, tt.wantOptions)
+		}
+		_ = client.Close()
+	}
+}
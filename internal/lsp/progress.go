@@ -0,0 +1,295 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+const (
+	// How long to wait after initialization for the server to start
+	// reporting work before assuming it has nothing to do
+	progressStartGrace = time.Second
+	// Longest WaitForServerReady waits for the server's startup work, such
+	// as indexing, to finish
+	serverReadyTimeout = 10 * time.Second
+)
+
+// workDoneProgress is an ongoing operation the server reports with $/progress,
+// such as loading packages or indexing
+type workDoneProgress struct {
+	title      string
+	message    string
+	percentage uint32
+	begun      bool
+}
+
+func (p *workDoneProgress) String() string {
+	text := p.title
+	if p.percentage > 0 {
+		text += fmt.Sprintf(" (%d%%)", p.percentage)
+	}
+	if p.message != "" {
+		text += ": " + p.message
+	}
+	return text
+}
+
+// ProgressUpdate is a begin, report or end notification for one of the
+// server's operations. Message and Percentage carry over from earlier updates
+// of the same operation when a later one leaves them out.
+type ProgressUpdate struct {
+	// "begin", "report" or "end"
+	Kind    string
+	Title   string
+	Message string
+	// Zero when the server doesn't report a percentage
+	Percentage uint32
+}
+
+func (u ProgressUpdate) String() string {
+	p := workDoneProgress{title: u.Title, message: u.Message, percentage: u.Percentage}
+	text := p.String()
+	if u.Kind == "end" {
+		text += " (done)"
+	}
+	return text
+}
+
+// OnProgress calls fn with every progress update from the server until the
+// returned function is called. fn runs on the message loop and must not block.
+func (c *Client) OnProgress(fn func(ProgressUpdate)) (remove func()) {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	if c.progressListeners == nil {
+		c.progressListeners = make(map[int]func(ProgressUpdate))
+	}
+	id := c.nextProgressListener
+	c.nextProgressListener++
+	c.progressListeners[id] = fn
+
+	return func() {
+		c.progressMu.Lock()
+		defer c.progressMu.Unlock()
+		delete(c.progressListeners, id)
+	}
+}
+
+// progressKey converts a progress token, which is either a number or a string,
+// to a map key
+func progressKey(token protocol.ProgressToken) string {
+	return fmt.Sprint(token.Value)
+}
+
+// HandleWorkDoneProgressCreate answers window/workDoneProgress/create by
+// starting to track the token
+func HandleWorkDoneProgressCreate(c *Client, params json.RawMessage) (any, error) {
+	var createParams protocol.WorkDoneProgressCreateParams
+	if err := json.Unmarshal(params, &createParams); err != nil {
+		lspLogger.Error("Error unmarshaling progress create params: %v", err)
+		return nil, err
+	}
+
+	c.progressMu.Lock()
+	c.progress[progressKey(createParams.Token)] = &workDoneProgress{}
+	c.progressMu.Unlock()
+
+	return nil, nil
+}
+
+// handleProgress updates the tracked operations from a $/progress
+// notification. It runs on the message loop rather than in its own goroutine
+// so that begin, report and end are applied in the order the server sent them.
+func (c *Client) handleProgress(params json.RawMessage) {
+	var progressParams protocol.ProgressParams
+	if err := json.Unmarshal(params, &progressParams); err != nil {
+		lspLogger.Error("Error unmarshaling progress params: %v", err)
+		return
+	}
+
+	value, ok := progressParams.Value.(map[string]any)
+	if !ok {
+		lspLogger.Debug("Ignoring progress notification with value %T", progressParams.Value)
+		return
+	}
+	kind, _ := value["kind"].(string)
+	title, _ := value["title"].(string)
+	message, _ := value["message"].(string)
+	percentage, _ := value["percentage"].(float64)
+
+	update, listeners, ok := c.updateProgress(progressKey(progressParams.Token), kind, title, message, uint32(percentage))
+	if !ok {
+		return
+	}
+	for _, listener := range listeners {
+		listener(update)
+	}
+}
+
+// updateProgress applies a progress notification to the tracked operations.
+// It returns the update to pass on and the listeners to pass it to, or false
+// if the notification was ignored.
+func (c *Client) updateProgress(key, kind, title, message string, percentage uint32) (ProgressUpdate, []func(ProgressUpdate), bool) {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	progress, known := c.progress[key]
+	switch kind {
+	case "begin":
+		if !known {
+			progress = &workDoneProgress{}
+			c.progress[key] = progress
+		}
+		progress.begun = true
+		progress.title = title
+		progress.message = message
+		progress.percentage = percentage
+		lspLogger.Debug("Progress started: %s", progress)
+	case "report":
+		if !known {
+			return ProgressUpdate{}, nil, false
+		}
+		if message != "" {
+			progress.message = message
+		}
+		if percentage > 0 {
+			progress.percentage = percentage
+		}
+	case "end":
+		if !known {
+			return ProgressUpdate{}, nil, false
+		}
+		if message != "" {
+			progress.message = message
+		}
+		lspLogger.Debug("Progress finished: %s", progress.title)
+		delete(c.progress, key)
+	default:
+		return ProgressUpdate{}, nil, false
+	}
+
+	// Wake anyone waiting for the work to change
+	close(c.progressChanged)
+	c.progressChanged = make(chan struct{})
+
+	update := ProgressUpdate{
+		Kind:       kind,
+		Title:      progress.title,
+		Message:    progress.message,
+		Percentage: progress.percentage,
+	}
+	listeners := make([]func(ProgressUpdate), 0, len(c.progressListeners))
+	for _, listener := range c.progressListeners {
+		listeners = append(listeners, listener)
+	}
+	return update, listeners, true
+}
+
+// resetProgress forgets all operations, for when the server that reported
+// them has exited
+func (c *Client) resetProgress() {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	c.progress = make(map[string]*workDoneProgress)
+	close(c.progressChanged)
+	c.progressChanged = make(chan struct{})
+}
+
+// WorkInProgress describes the operations the server is currently reporting
+// progress for, such as indexing. It is empty when the server is idle.
+func (c *Client) WorkInProgress() []string {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	var work []string
+	for _, progress := range c.progress {
+		if progress.begun {
+			work = append(work, progress.String())
+		}
+	}
+	sort.Strings(work)
+	return work
+}
+
+// busy reports whether any operation is in progress, along with a channel
+// that is closed when that changes
+func (c *Client) busy() (bool, <-chan struct{}) {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	for _, progress := range c.progress {
+		if progress.begun {
+			return true, c.progressChanged
+		}
+	}
+	return false, c.progressChanged
+}
+
+// WaitForIdle waits until the server has no operations in progress, for at
+// most timeout. It reports whether the server is idle.
+func (c *Client) WaitForIdle(ctx context.Context, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		busy, changed := c.busy()
+		if !busy {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// WaitForServerReady waits for the work the server starts after
+// initialization, such as loading the workspace and indexing, to finish. The
+// wait is bounded, so the server may still be busy when it returns.
+func (c *Client) WaitForServerReady(ctx context.Context) error {
+	// Servers begin reporting progress shortly after initialization, so give
+	// them a moment to start before checking whether they are busy
+	graceDuration, timeout := progressStartGrace, serverReadyTimeout
+	if c.preset != nil {
+		if c.preset.Readiness.ProgressGrace > 0 {
+			graceDuration = time.Duration(c.preset.Readiness.ProgressGrace)
+		}
+		if c.preset.Readiness.Timeout > 0 {
+			timeout = time.Duration(c.preset.Readiness.Timeout)
+		}
+	}
+
+	grace := time.NewTimer(graceDuration)
+	defer grace.Stop()
+	for {
+		busy, changed := c.busy()
+		if busy {
+			break
+		}
+		select {
+		case <-changed:
+		case <-grace.C:
+			// No work was started
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if !c.WaitForIdle(ctx, timeout) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lspLogger.Warn("Server is still busy after %v: %v", timeout, c.WorkInProgress())
+	}
+	return nil
+}
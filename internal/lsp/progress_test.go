@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newProgressClient() *Client {
+	return &Client{
+		progress:        make(map[string]*workDoneProgress),
+		progressChanged: make(chan struct{}),
+	}
+}
+
+func sendProgress(c *Client, token any, value map[string]any) {
+	params, _ := json.Marshal(map[string]any{"token": token, "value": value})
+	c.handleProgress(params)
+}
+
+func TestHandleProgress_TracksWork(t *testing.T) {
+	client := newProgressClient()
+
+	params, _ := json.Marshal(map[string]any{"token": "rustAnalyzer/Indexing"})
+	if _, err := HandleWorkDoneProgressCreate(client, params); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if work := client.WorkInProgress(); len(work) != 0 {
+		t.Fatalf("expected no work before begin, got %v", work)
+	}
+
+	sendProgress(client, "rustAnalyzer/Indexing", map[string]any{"kind": "begin", "title": "Indexing", "percentage": 0})
+	sendProgress(client, 7, map[string]any{"kind": "begin", "title": "Loading packages", "message": "example.com/mod"})
+	sendProgress(client, "rustAnalyzer/Indexing", map[string]any{"kind": "report", "message": "12/40 (core)", "percentage": 30})
+
+	expected := []string{"Indexing (30%): 12/40 (core)", "Loading packages: example.com/mod"}
+	if work := client.WorkInProgress(); !reflect.DeepEqual(work, expected) {
+		t.Errorf("expected %v, got %v", expected, work)
+	}
+
+	sendProgress(client, 7, map[string]any{"kind": "end"})
+	sendProgress(client, "rustAnalyzer/Indexing", map[string]any{"kind": "end", "message": "done"})
+	if work := client.WorkInProgress(); len(work) != 0 {
+		t.Errorf("expected no work after end, got %v", work)
+	}
+
+	// Reports for unknown tokens are ignored
+	sendProgress(client, "unknown", map[string]any{"kind": "report", "message": "stray"})
+	if work := client.WorkInProgress(); len(work) != 0 {
+		t.Errorf("expected stray report to be ignored, got %v", work)
+	}
+}
+
+func TestWaitForIdle(t *testing.T) {
+	client := newProgressClient()
+	ctx := context.Background()
+
+	if !client.WaitForIdle(ctx, time.Second) {
+		t.Fatal("expected an idle server to return immediately")
+	}
+
+	sendProgress(client, "token", map[string]any{"kind": "begin", "title": "Indexing"})
+	if client.WaitForIdle(ctx, 50*time.Millisecond) {
+		t.Fatal("expected a busy server to time out")
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		sendProgress(client, "token", map[string]any{"kind": "end"})
+	}()
+	start := time.Now()
+	if !client.WaitForIdle(ctx, 5*time.Second) {
+		t.Fatal("expected the server to become idle")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected WaitForIdle to return when the work ended, took %v", elapsed)
+	}
+}
+
+func TestWaitForServerReady_WaitsForStartupWork(t *testing.T) {
+	client := newProgressClient()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		sendProgress(client, "load", map[string]any{"kind": "begin", "title": "Loading packages"})
+		time.Sleep(50 * time.Millisecond)
+		sendProgress(client, "load", map[string]any{"kind": "end"})
+	}()
+
+	if err := client.WaitForServerReady(context.Background()); err != nil {
+		t.Fatalf("WaitForServerReady failed: %v", err)
+	}
+	if work := client.WorkInProgress(); len(work) != 0 {
+		t.Errorf("expected startup work to have finished, got %v", work)
+	}
+}
+
+func TestOnProgress_RelaysUpdates(t *testing.T) {
+	client := newProgressClient()
+
+	var updates []ProgressUpdate
+	remove := client.OnProgress(func(update ProgressUpdate) {
+		updates = append(updates, update)
+	})
+
+	sendProgress(client, "index", map[string]any{"kind": "begin", "title": "Indexing", "message": "0/40"})
+	sendProgress(client, "index", map[string]any{"kind": "report", "percentage": 50})
+	sendProgress(client, "index", map[string]any{"kind": "end"})
+
+	expected := []ProgressUpdate{
+		{Kind: "begin", Title: "Indexing", Message: "0/40"},
+		{Kind: "report", Title: "Indexing", Message: "0/40", Percentage: 50},
+		{Kind: "end", Title: "Indexing", Message: "0/40", Percentage: 50},
+	}
+	if !reflect.DeepEqual(updates, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, updates)
+	}
+	if text := updates[1].String(); text != "Indexing (50%): 0/40" {
+		t.Errorf("unexpected report text %q", text)
+	}
+	if text := updates[2].String(); text != "Indexing (50%): 0/40 (done)" {
+		t.Errorf("unexpected end text %q", text)
+	}
+
+	remove()
+	sendProgress(client, "index", map[string]any{"kind": "begin", "title": "Indexing"})
+	if len(updates) != 3 {
+		t.Errorf("expected no updates after remove, got %d", len(updates))
+	}
+}
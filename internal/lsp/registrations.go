@@ -0,0 +1,124 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// methodProviders maps requests to the server capability that advertises them
+var methodProviders = map[string]string{
+	"textDocument/hover":                "hoverProvider",
+	"textDocument/completion":           "completionProvider",
+	"textDocument/signatureHelp":        "signatureHelpProvider",
+	"textDocument/definition":           "definitionProvider",
+	"textDocument/declaration":          "declarationProvider",
+	"textDocument/typeDefinition":       "typeDefinitionProvider",
+	"textDocument/implementation":       "implementationProvider",
+	"textDocument/references":           "referencesProvider",
+	"textDocument/documentSymbol":       "documentSymbolProvider",
+	"textDocument/codeAction":           "codeActionProvider",
+	"textDocument/codeLens":             "codeLensProvider",
+	"textDocument/formatting":           "documentFormattingProvider",
+	"textDocument/rangeFormatting":      "documentRangeFormattingProvider",
+	"textDocument/rename":               "renameProvider",
+	"textDocument/prepareCallHierarchy": "callHierarchyProvider",
+	"textDocument/prepareTypeHierarchy": "typeHierarchyProvider",
+	"textDocument/inlayHint":            "inlayHintProvider",
+	"textDocument/diagnostic":           "diagnosticProvider",
+	"workspace/symbol":                  "workspaceSymbolProvider",
+	"workspace/executeCommand":          "executeCommandProvider",
+}
+
+// SupportsMethod reports whether the server can answer a request, either
+// because it advertised the capability during initialization or because it
+// registered the method later with client/registerCapability. Methods without
+// a known capability are assumed to be supported.
+func (c *Client) SupportsMethod(method string) bool {
+	if c.isRegistered(method) {
+		return true
+	}
+
+	provider, known := methodProviders[method]
+	if !known {
+		return true
+	}
+
+	// Providers are booleans, options objects or registration options
+	// depending on the server, so look at their JSON form
+	data, err := json.Marshal(c.ServerCapabilities())
+	if err != nil {
+		lspLogger.Error("Failed to encode server capabilities: %v", err)
+		return true
+	}
+	var capabilities map[string]any
+	if err := json.Unmarshal(data, &capabilities); err != nil {
+		lspLogger.Error("Failed to decode server capabilities: %v", err)
+		return true
+	}
+
+	switch v := capabilities[provider].(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
+
+// isRegistered reports whether the server has dynamically registered method
+func (c *Client) isRegistered(method string) bool {
+	c.registrationsMu.RLock()
+	defer c.registrationsMu.RUnlock()
+
+	for _, registration := range c.registrations {
+		if registration.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Registrations returns the capabilities the server has registered since it
+// was initialized
+func (c *Client) Registrations() []protocol.Registration {
+	c.registrationsMu.RLock()
+	defer c.registrationsMu.RUnlock()
+
+	registrations := make([]protocol.Registration, 0, len(c.registrations))
+	for _, registration := range c.registrations {
+		registrations = append(registrations, registration)
+	}
+	return registrations
+}
+
+// addRegistration records a capability registered by the server
+func (c *Client) addRegistration(registration protocol.Registration) {
+	c.registrationsMu.Lock()
+	defer c.registrationsMu.Unlock()
+
+	if c.registrations == nil {
+		c.registrations = make(map[string]protocol.Registration)
+	}
+	c.registrations[registration.ID] = registration
+}
+
+// removeRegistration forgets a registration, returning false if it is unknown
+func (c *Client) removeRegistration(id string) (protocol.Registration, bool) {
+	c.registrationsMu.Lock()
+	defer c.registrationsMu.Unlock()
+
+	registration, ok := c.registrations[id]
+	delete(c.registrations, id)
+	return registration, ok
+}
+
+// resetRegistrations forgets all registrations, for when a new server process
+// is initialized
+func (c *Client) resetRegistrations() {
+	c.registrationsMu.Lock()
+	defer c.registrationsMu.Unlock()
+
+	c.registrations = make(map[string]protocol.Registration)
+}
@@ -0,0 +1,98 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func TestSupportsMethod_Static(t *testing.T) {
+	// Providers come as booleans or options objects depending on the server
+	var capabilities protocol.ServerCapabilities
+	err := json.Unmarshal([]byte(`{
+		"hoverProvider": true,
+		"definitionProvider": false,
+		"renameProvider": {"prepareProvider": true},
+		"completionProvider": {},
+		"typeHierarchyProvider": null,
+		"documentSymbolProvider": {"label": "symbols"}
+	}`), &capabilities)
+	if err != nil {
+		t.Fatalf("Failed to decode capabilities: %v", err)
+	}
+	client := &Client{serverCapabilities: capabilities}
+
+	tests := map[string]bool{
+		"textDocument/hover":                true,
+		"textDocument/definition":           false,
+		"textDocument/rename":               true,
+		"textDocument/completion":           true,
+		"textDocument/prepareTypeHierarchy": false,
+		"textDocument/documentSymbol":       true,
+		"textDocument/references":           false,
+		// Methods without a capability are assumed to be supported
+		"textDocument/didOpen": true,
+	}
+	for method, expected := range tests {
+		if got := client.SupportsMethod(method); got != expected {
+			t.Errorf("SupportsMethod(%s) = %v, want %v", method, got, expected)
+		}
+	}
+}
+
+func TestSupportsMethod_Registrations(t *testing.T) {
+	client := &Client{}
+	client.resetRegistrations()
+
+	if client.SupportsMethod("textDocument/formatting") {
+		t.Fatal("expected formatting to be unsupported before registration")
+	}
+
+	register, _ := json.Marshal(protocol.RegistrationParams{Registrations: []protocol.Registration{
+		{ID: "fmt", Method: "textDocument/formatting"},
+	}})
+	if _, err := HandleRegisterCapability(client, register); err != nil {
+		t.Fatalf("HandleRegisterCapability() error = %v", err)
+	}
+	if !client.SupportsMethod("textDocument/formatting") {
+		t.Error("expected formatting to be supported after registration")
+	}
+	if got := len(client.Registrations()); got != 1 {
+		t.Errorf("expected 1 registration, got %d", got)
+	}
+
+	unregister, _ := json.Marshal(protocol.UnregistrationParams{Unregisterations: []protocol.Unregistration{
+		{ID: "fmt", Method: "textDocument/formatting"},
+	}})
+	if _, err := HandleUnregisterCapability(client, unregister); err != nil {
+		t.Fatalf("HandleUnregisterCapability() error = %v", err)
+	}
+	if client.SupportsMethod("textDocument/formatting") {
+		t.Error("expected formatting to be unsupported after unregistration")
+	}
+	if got := len(client.Registrations()); got != 0 {
+		t.Errorf("expected no registrations, got %d", got)
+	}
+}
+
+func TestRegisterFileWatchHandler_PerClient(t *testing.T) {
+	first, second := &Client{}, &Client{}
+	var got []string
+	first.RegisterFileWatchHandler(func(id string, watchers []protocol.FileSystemWatcher) {
+		got = append(got, "first:"+id)
+	})
+	second.RegisterFileWatchHandler(func(id string, watchers []protocol.FileSystemWatcher) {
+		got = append(got, "second:"+id)
+	})
+
+	params, _ := json.Marshal(protocol.RegistrationParams{Registrations: []protocol.Registration{
+		{ID: "watch", Method: "workspace/didChangeWatchedFiles", RegisterOptions: map[string]any{"watchers": []any{}}},
+	}})
+	if _, err := HandleRegisterCapability(second, params); err != nil {
+		t.Fatalf("HandleRegisterCapability() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != "second:watch" {
+		t.Errorf("expected only the second client's handler to be called, got %v", got)
+	}
+}
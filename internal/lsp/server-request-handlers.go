@@ -1,6 +1,7 @@
 package lsp
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/vector67/mcp-language-server/internal/protocol"
@@ -10,21 +11,47 @@ import (
 // FileWatchHandler is called when file watchers are registered by the server
 type FileWatchHandler func(id string, watchers []protocol.FileSystemWatcher)
 
-// fileWatchHandler holds the current file watch handler
-var fileWatchHandler FileWatchHandler
+// FileUnwatchHandler is called when the server unregisters file watchers
+type FileUnwatchHandler func(id string)
 
-// RegisterFileWatchHandler registers a handler for file watcher registrations
-func RegisterFileWatchHandler(handler FileWatchHandler) {
-	fileWatchHandler = handler
+// RegisterFileWatchHandler registers a handler for the file watchers this
+// client's server registers
+func (c *Client) RegisterFileWatchHandler(handler FileWatchHandler) {
+	c.registrationsMu.Lock()
+	defer c.registrationsMu.Unlock()
+	c.fileWatchHandler = handler
+}
+
+// RegisterFileUnwatchHandler registers a handler for the file watchers this
+// client's server unregisters
+func (c *Client) RegisterFileUnwatchHandler(handler FileUnwatchHandler) {
+	c.registrationsMu.Lock()
+	defer c.registrationsMu.Unlock()
+	c.fileUnwatchHandler = handler
 }
 
 // Requests
 
-func HandleWorkspaceConfiguration(params json.RawMessage) (any, error) {
-	return []map[string]any{{}}, nil
+// HandleWorkspaceConfiguration answers workspace/configuration with the
+// settings for each requested section
+func HandleWorkspaceConfiguration(c *Client, params json.RawMessage) (any, error) {
+	var configParams protocol.ConfigurationParams
+	if err := json.Unmarshal(params, &configParams); err != nil {
+		lspLogger.Error("Error unmarshaling configuration params: %v", err)
+		return nil, err
+	}
+
+	results := make([]any, len(configParams.Items))
+	for i, item := range configParams.Items {
+		results[i] = c.ConfigurationSection(item.Section)
+	}
+	return results, nil
 }
 
-func HandleRegisterCapability(params json.RawMessage) (any, error) {
+// HandleRegisterCapability records the capabilities the server registers, so
+// tools can tell which requests it now supports, and passes file watcher
+// registrations on to the file watcher
+func HandleRegisterCapability(c *Client, params json.RawMessage) (any, error) {
 	var registerParams protocol.RegistrationParams
 	if err := json.Unmarshal(params, &registerParams); err != nil {
 		lspLogger.Error("Error unmarshaling registration params: %v", err)
@@ -33,6 +60,7 @@ func HandleRegisterCapability(params json.RawMessage) (any, error) {
 
 	for _, reg := range registerParams.Registrations {
 		lspLogger.Info("Registration received for method: %s, id: %s", reg.Method, reg.ID)
+		c.addRegistration(reg)
 
 		// Special handling for file watcher registrations
 		if reg.Method == "workspace/didChangeWatchedFiles" {
@@ -51,8 +79,11 @@ func HandleRegisterCapability(params json.RawMessage) (any, error) {
 			}
 
 			// Notify file watchers
-			if fileWatchHandler != nil {
-				fileWatchHandler(reg.ID, opts.Watchers)
+			c.registrationsMu.RLock()
+			handler := c.fileWatchHandler
+			c.registrationsMu.RUnlock()
+			if handler != nil {
+				handler(reg.ID, opts.Watchers)
 			}
 		}
 	}
@@ -60,14 +91,41 @@ func HandleRegisterCapability(params json.RawMessage) (any, error) {
 	return nil, nil
 }
 
-func HandleApplyEdit(params json.RawMessage) (any, error) {
+// HandleUnregisterCapability forgets the capabilities the server unregisters
+func HandleUnregisterCapability(c *Client, params json.RawMessage) (any, error) {
+	var unregisterParams protocol.UnregistrationParams
+	if err := json.Unmarshal(params, &unregisterParams); err != nil {
+		lspLogger.Error("Error unmarshaling unregistration params: %v", err)
+		return nil, err
+	}
+
+	for _, unreg := range unregisterParams.Unregisterations {
+		lspLogger.Info("Unregistration received for method: %s, id: %s", unreg.Method, unreg.ID)
+		if _, ok := c.removeRegistration(unreg.ID); !ok {
+			lspLogger.Warn("Unknown registration id: %s", unreg.ID)
+		}
+
+		c.registrationsMu.RLock()
+		handler := c.fileUnwatchHandler
+		c.registrationsMu.RUnlock()
+		if unreg.Method == "workspace/didChangeWatchedFiles" && handler != nil {
+			handler(unreg.ID)
+		}
+	}
+
+	return nil, nil
+}
+
+// HandleApplyEdit processes workspace/applyEdit requests, which servers send
+// while executing commands (e.g. code actions)
+func HandleApplyEdit(client *Client, params json.RawMessage) (any, error) {
 	var workspaceEdit protocol.ApplyWorkspaceEditParams
 	if err := json.Unmarshal(params, &workspaceEdit); err != nil {
 		return protocol.ApplyWorkspaceEditResult{Applied: false}, err
 	}
 
 	// Apply the edits
-	err := utilities.ApplyWorkspaceEdit(workspaceEdit.Edit)
+	err := utilities.ApplyWorkspaceEdit(workspaceEdit.Edit, client.PositionEncoding())
 	if err != nil {
 		lspLogger.Error("Error applying workspace edit: %v", err)
 		return protocol.ApplyWorkspaceEditResult{
@@ -76,11 +134,58 @@ func HandleApplyEdit(params json.RawMessage) (any, error) {
 		}, nil
 	}
 
+	// Keep the server's view of open documents in sync with the disk
+	for _, path := range utilities.AffectedFiles(workspaceEdit.Edit) {
+		if err := client.NotifyChange(context.Background(), path); err != nil {
+			lspLogger.Warn("Failed to notify change to %s: %v", path, err)
+		}
+	}
+
 	return protocol.ApplyWorkspaceEditResult{
 		Applied: true,
 	}, nil
 }
 
+// HandleDiagnosticRefresh answers workspace/diagnostic/refresh. Diagnostics
+// are pulled each time they are asked for, so there is nothing to refresh.
+func HandleDiagnosticRefresh(params json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+// HandleShowMessageRequest logs a window/showMessageRequest. There is no user
+// to pick one of the actions, so none is chosen.
+func HandleShowMessageRequest(params json.RawMessage) (any, error) {
+	var request protocol.ShowMessageRequestParams
+	if err := json.Unmarshal(params, &request); err != nil {
+		lspLogger.Error("Error unmarshaling message request: %v", err)
+		return nil, err
+	}
+
+	logServerMessage(request.Type, request.Message)
+	return nil, nil
+}
+
+// HandleShowDocument logs the document a window/showDocument request asks to
+// show. External documents, which would be opened in a browser, are refused.
+func HandleShowDocument(params json.RawMessage) (any, error) {
+	var showParams protocol.ShowDocumentParams
+	if err := json.Unmarshal(params, &showParams); err != nil {
+		lspLogger.Error("Error unmarshaling show document params: %v", err)
+		return nil, err
+	}
+
+	if showParams.External {
+		lspLogger.Info("Server asked to open %s externally", showParams.URI)
+		return protocol.ShowDocumentResult{Success: false}, nil
+	}
+	if showParams.Selection != nil {
+		lspLogger.Info("Server asked to show %s at L%d", showParams.URI, showParams.Selection.Start.Line+1)
+	} else {
+		lspLogger.Info("Server asked to show %s", showParams.URI)
+	}
+	return protocol.ShowDocumentResult{Success: true}, nil
+}
+
 func workspaceEditFailure(err error) string {
 	if err == nil {
 		return ""
@@ -98,16 +203,37 @@ func HandleServerMessage(params json.RawMessage) {
 		return
 	}
 
-	// Log the message with appropriate level
-	switch msg.Type {
+	logServerMessage(msg.Type, msg.Message)
+}
+
+// HandleLogMessage processes window/logMessage notifications from the server
+func HandleLogMessage(params json.RawMessage) {
+	var msg protocol.LogMessageParams
+	if err := json.Unmarshal(params, &msg); err != nil {
+		lspLogger.Error("Error unmarshaling log message: %v", err)
+		return
+	}
+
+	// Servers log freely, so keep their log out of the way unless it reports
+	// a problem
+	if msg.Type == protocol.Error || msg.Type == protocol.Warning {
+		logServerMessage(msg.Type, msg.Message)
+		return
+	}
+	lspLogger.Debug("Server log: %s", msg.Message)
+}
+
+// logServerMessage logs a message from the server at the matching level
+func logServerMessage(messageType protocol.MessageType, message string) {
+	switch messageType {
 	case protocol.Error:
-		lspLogger.Error("Server error: %s", msg.Message)
+		lspLogger.Error("Server error: %s", message)
 	case protocol.Warning:
-		lspLogger.Warn("Server warning: %s", msg.Message)
+		lspLogger.Warn("Server warning: %s", message)
 	case protocol.Info:
-		lspLogger.Info("Server info: %s", msg.Message)
+		lspLogger.Info("Server info: %s", message)
 	default:
-		lspLogger.Debug("Server message: %s", msg.Message)
+		lspLogger.Debug("Server message: %s", message)
 	}
 }
 
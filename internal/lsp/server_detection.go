@@ -7,50 +7,144 @@ import (
 	"strings"
 )
 
-type ServerDetection struct {
-	Command string
-	Args    []string
+// DefaultDetectDepth is how many directory levels below the workspace root
+// are searched for project roots
+const DefaultDetectDepth = 2
+
+// ProjectRoot is a directory with the marker files of a preset's project
+type ProjectRoot struct {
+	Dir    string
+	Preset *Preset
 }
 
-type detectionRule struct {
-	marker  string
-	command string
-	args    []string
+// DetectServer returns the built-in preset for the server of the project in
+// workspaceDir. When workspaceDir is the root of several projects, the
+// preset that comes first wins.
+func DetectServer(workspaceDir string) (*Preset, error) {
+	roots, err := BuiltinPresets().DetectRoots(workspaceDir, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return roots[0].Preset, nil
 }
 
-var detectionRules = []detectionRule{
-	{"go.mod", "gopls", nil},
-	{"go.sum", "gopls", nil},
-	{"Cargo.toml", "rust-analyzer", nil},
-	{"tsconfig.json", "typescript-language-server", []string{"--stdio"}},
-	{"package.json", "typescript-language-server", []string{"--stdio"}},
-	{"pyproject.toml", "pyright-langserver", []string{"--stdio"}},
-	{"setup.py", "pyright-langserver", []string{"--stdio"}},
-	{"requirements.txt", "pyright-langserver", []string{"--stdio"}},
-	{"compile_commands.json", "clangd", nil},
-	{"CMakeLists.txt", "clangd", nil},
+// DetectRoots finds the project roots in workspaceDir and the directories up
+// to depth levels below it, for monorepos whose projects live in
+// subdirectories. A directory is a root of every preset with a marker file
+// in it, in preset order, so one with both a go.mod and a package.json holds
+// two projects. Hidden directories, node_modules and the directories skip
+// reports are not searched.
+func (p Presets) DetectRoots(workspaceDir string, depth int, skip func(path string, isDir bool) bool) ([]ProjectRoot, error) {
+	var roots []ProjectRoot
+	err := filepath.WalkDir(workspaceDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == workspaceDir {
+				return err
+			}
+			// Skip directories that can't be read
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != workspaceDir {
+			rel, err := filepath.Rel(workspaceDir, path)
+			if err != nil {
+				return filepath.SkipDir
+			}
+			level := strings.Count(rel, string(filepath.Separator)) + 1
+			name := d.Name()
+			if level > depth || strings.HasPrefix(name, ".") || name == "node_modules" || (skip != nil && skip(path, true)) {
+				return filepath.SkipDir
+			}
+		}
+
+		for _, preset := range p.detectIn(path) {
+			roots = append(roots, ProjectRoot{Dir: path, Preset: preset})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search workspace for projects: %w", err)
+	}
+	if len(roots) == 0 {
+		return nil, p.notDetected(workspaceDir)
+	}
+	return roots, nil
 }
 
-func DetectServer(workspaceDir string) (*ServerDetection, error) {
-	for _, rule := range detectionRules {
-		path := filepath.Join(workspaceDir, rule.marker)
-		if _, err := os.Stat(path); err == nil {
-			return &ServerDetection{
-				Command: rule.command,
-				Args:    rule.args,
-			}, nil
+// detectIn returns the presets with a marker file in dir
+func (p Presets) detectIn(dir string) []*Preset {
+	var detected []*Preset
+	for i := range p {
+		for _, marker := range p[i].Markers {
+			path := filepath.Join(dir, marker)
+			if _, err := os.Stat(path); err == nil {
+				detected = append(detected, &p[i])
+				break
+			}
 		}
 	}
+	return detected
+}
 
+func (p Presets) notDetected(workspaceDir string) error {
 	var markers []string
-	for _, rule := range detectionRules {
-		markers = append(markers, rule.marker)
+	for _, preset := range p {
+		markers = append(markers, preset.Markers...)
 	}
 
-	return nil, fmt.Errorf(
+	return fmt.Errorf(
 		"could not detect LSP server for workspace %s: no recognized project files found. "+
 			"Looked for: %s. Use --lsp to specify the server manually",
 		workspaceDir,
 		strings.Join(markers, ", "),
 	)
 }
+
+// ServerConfigsForRoots returns a server for each preset among roots, rooted
+// at that preset's project roots. Roots inside another root of the same
+// preset are dropped, as the server already covers them. A single server
+// handles every file; with several, each handles its preset's languages.
+func ServerConfigsForRoots(roots []ProjectRoot) []ServerConfig {
+	var configs []ServerConfig
+	byPreset := make(map[*Preset]int)
+	for _, root := range roots {
+		i, ok := byPreset[root.Preset]
+		if !ok {
+			i = len(configs)
+			byPreset[root.Preset] = i
+			configs = append(configs, root.Preset.ServerConfig())
+		}
+		configs[i].Roots = addRoot(configs[i].Roots, root.Dir)
+	}
+
+	if len(configs) == 1 {
+		configs[0].Languages = nil
+	}
+	return configs
+}
+
+// addRoot adds dir to roots unless it is inside one of them, dropping the
+// roots inside dir
+func addRoot(roots []string, dir string) []string {
+	for _, root := range roots {
+		if within(dir, root) {
+			return roots
+		}
+	}
+	var kept []string
+	for _, root := range roots {
+		if !within(root, dir) {
+			kept = append(kept, root)
+		}
+	}
+	return append(kept, dir)
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
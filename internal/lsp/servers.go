@@ -0,0 +1,83 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ServerConfig describes a language server and the files it handles
+type ServerConfig struct {
+	Command string
+	Args    []string
+	// Network ("unix" or "tcp") and address of a server that is already
+	// running, used instead of Command
+	Network string
+	Address string
+	// Language IDs, as returned by DetectLanguageID, and file extensions
+	// including the dot. A server without any handles the files no other
+	// server does.
+	Languages []string
+	// Preset that sets the server up, if any
+	Preset *Preset
+	// Project roots the server is initialized with as its workspace
+	// folders, the first as its root. Without any the workspace is used.
+	Roots []string
+}
+
+// ParseServerConfig parses a server given as the languages and extensions it
+// handles and the command line that starts it, such as
+// "typescript,.tsx=typescript-language-server --stdio"
+func ParseServerConfig(value string) (ServerConfig, error) {
+	languages, command, found := strings.Cut(value, "=")
+	if !found {
+		return ServerConfig{}, fmt.Errorf("invalid server %q: expected <languages>=<command>", value)
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ServerConfig{}, fmt.Errorf("invalid server %q: missing command", value)
+	}
+
+	config := ServerConfig{Command: fields[0], Args: fields[1:]}
+	for _, language := range strings.Split(languages, ",") {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if language != "" {
+			config.Languages = append(config.Languages, language)
+		}
+	}
+	if len(config.Languages) == 0 {
+		return ServerConfig{}, fmt.Errorf("invalid server %q: no languages given", value)
+	}
+	return config, nil
+}
+
+// Handles reports whether the server is configured for the file at path,
+// either by its language or by its extension
+func (c ServerConfig) Handles(path string) bool {
+	language := strings.ToLower(string(DetectLanguageID(path)))
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, handled := range c.Languages {
+		if handled == language || (ext != "" && handled == ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transport returns how to reach the server: by connecting to its address if
+// it has one, and otherwise by starting its command
+func (c ServerConfig) Transport() Transport {
+	if c.Address != "" {
+		return SocketTransport{Network: c.Network, Address: c.Address}
+	}
+	return ProcessTransport{Command: c.Command, Args: c.Args}
+}
+
+// String returns the server's command line, or the address it listens on
+func (c ServerConfig) String() string {
+	if c.Address != "" {
+		return c.Network + ":" + c.Address
+	}
+	return strings.Join(append([]string{c.Command}, c.Args...), " ")
+}
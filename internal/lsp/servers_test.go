@@ -0,0 +1,64 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseServerConfig(t *testing.T) {
+	config, err := ParseServerConfig("TypeScript, .tsx=typescript-language-server --stdio")
+	if err != nil {
+		t.Fatalf("ParseServerConfig() error = %v", err)
+	}
+	expected := ServerConfig{
+		Command:   "typescript-language-server",
+		Args:      []string{"--stdio"},
+		Languages: []string{"typescript", ".tsx"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("ParseServerConfig() = %+v, want %+v", config, expected)
+	}
+
+	for _, value := range []string{"gopls", "go=", "=gopls", " , =gopls"} {
+		if _, err := ParseServerConfig(value); err == nil {
+			t.Errorf("ParseServerConfig(%q) expected an error", value)
+		}
+	}
+}
+
+func TestServerConfig_Handles(t *testing.T) {
+	config := ServerConfig{Command: "pyright-langserver", Languages: []string{"python", ".pyi"}}
+	tests := map[string]bool{
+		"/workspace/main.py":     true,
+		"/workspace/stubs.pyi":   true,
+		"/workspace/MAIN.PY":     true,
+		"/workspace/main.go":     false,
+		"/workspace/Makefile":    false,
+		"/workspace/python/x.ts": false,
+	}
+	for path, expected := range tests {
+		if got := config.Handles(path); got != expected {
+			t.Errorf("Handles(%s) = %v, want %v", path, got, expected)
+		}
+	}
+
+	// A default server is only used when no other server handles a file
+	if (ServerConfig{Command: "gopls"}).Handles("/workspace/main.go") {
+		t.Error("expected a server without languages not to handle files itself")
+	}
+}
+
+func TestServerConfig_Transport(t *testing.T) {
+	process := ServerConfig{Command: "gopls", Args: []string{"serve"}}
+	if transport, ok := process.Transport().(ProcessTransport); !ok || transport.Command != "gopls" || !transport.Owned() {
+		t.Errorf("expected a server with a command to be started, got %#v", process.Transport())
+	}
+
+	socket := ServerConfig{Network: "tcp", Address: "localhost:4389"}
+	if transport, ok := socket.Transport().(SocketTransport); !ok || transport.Address != "localhost:4389" || transport.Owned() {
+		t.Errorf("expected a server with an address to be connected to, got %#v", socket.Transport())
+	}
+	if got := socket.String(); got != "tcp:localhost:4389" {
+		t.Errorf("String() = %q, want %q", got, "tcp:localhost:4389")
+	}
+}
@@ -0,0 +1,142 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// SettingsFileName is the settings file looked for in the workspace root when
+// no settings file is given explicitly
+const SettingsFileName = ".mcp-language-server.json"
+
+// LoadSettings reads a settings file. The file holds a JSON object whose keys
+// are configuration sections, either nested ({"gopls": {"buildFlags": [...]}})
+// or dotted ({"python.analysis.extraPaths": [...]}).
+func LoadSettings(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+	return expandSettings(settings), nil
+}
+
+// SetSettings replaces the settings served to the language server. Once the
+// server is initialized the new settings are pushed to it with
+// workspace/didChangeConfiguration.
+func (c *Client) SetSettings(ctx context.Context, settings map[string]any) error {
+	c.settingsMu.Lock()
+	c.settings = settings
+	c.settingsMu.Unlock()
+
+	if !c.initialized.Load() {
+		return nil
+	}
+	return c.pushSettings(ctx)
+}
+
+// pushSettings sends the current settings with workspace/didChangeConfiguration
+func (c *Client) pushSettings(ctx context.Context) error {
+	return c.DidChangeConfiguration(ctx, protocol.DidChangeConfigurationParams{
+		Settings: c.allSettings(),
+	})
+}
+
+// SetPreset sets up the client for the server of preset, whose settings the
+// settings file is merged over. It must be called before the server is
+// initialized.
+func (c *Client) SetPreset(preset *Preset) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+	c.preset = preset
+}
+
+// allSettings returns the settings file's settings merged over the preset's
+func (c *Client) allSettings() map[string]any {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	var defaults map[string]any
+	if c.preset != nil {
+		defaults = expandSettings(c.preset.Settings)
+	}
+	return mergeSettings(defaults, c.settings)
+}
+
+// ConfigurationSection returns the settings for a workspace/configuration
+// section, such as "gopls" or "python.analysis". The empty section returns
+// all settings. Unknown sections return an empty object.
+func (c *Client) ConfigurationSection(section string) any {
+	settings := c.allSettings()
+	if section == "" {
+		return settings
+	}
+	if value, ok := lookupSetting(settings, section); ok {
+		return value
+	}
+	return map[string]any{}
+}
+
+// lookupSetting finds the value at a dotted path such as "python.analysis"
+func lookupSetting(settings map[string]any, path string) (any, bool) {
+	var value any = settings
+	for _, part := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// expandSettings turns top-level dotted keys, as used in VS Code's
+// settings.json, into nested objects so every section can be looked up by
+// path. Keys inside values are left alone, since some settings use URLs or
+// globs as keys.
+func expandSettings(settings map[string]any) map[string]any {
+	expanded := map[string]any{}
+	for key, value := range settings {
+		expanded = mergeSettings(expanded, expandDottedKey(key, value))
+	}
+	return expanded
+}
+
+// expandDottedKey turns "a.b" and value into {"a": {"b": value}}
+func expandDottedKey(key string, value any) map[string]any {
+	parts := strings.Split(key, ".")
+	result := map[string]any{parts[len(parts)-1]: value}
+	for i := len(parts) - 2; i >= 0; i-- {
+		result = map[string]any{parts[i]: result}
+	}
+	return result
+}
+
+// mergeSettings returns base with override merged over it. Objects are merged
+// key by key; any other value in override replaces the one in base.
+func mergeSettings(base, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		baseObject, baseIsObject := merged[key].(map[string]any)
+		overrideObject, overrideIsObject := value.(map[string]any)
+		if baseIsObject && overrideIsObject {
+			merged[key] = mergeSettings(baseObject, overrideObject)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
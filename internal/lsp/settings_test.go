@@ -0,0 +1,130 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSettings_ConfigurationSections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SettingsFileName)
+	content := `{
+		"gopls": {"buildFlags": ["-tags=integration"]},
+		"python.analysis.extraPaths": ["./vendor"],
+		"python.analysis": {"typeCheckingMode": "strict"},
+		"rust-analyzer.cargo.features": ["serde"],
+		"yaml.schemas": {"https://json.schemastore.org/github-workflow.json": ".github/workflows/*"}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+
+	settings, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	client := &Client{settings: settings, preset: BuiltinPresets().Find("rust-analyzer")}
+
+	tests := []struct {
+		section  string
+		expected any
+	}{
+		{"gopls", map[string]any{"buildFlags": []any{"-tags=integration"}}},
+		{"gopls.buildFlags", []any{"-tags=integration"}},
+		{"python.analysis", map[string]any{"extraPaths": []any{"./vendor"}, "typeCheckingMode": "strict"}},
+		{"python.analysis.extraPaths", []any{"./vendor"}},
+		{"rust-analyzer.cargo.features", []any{"serde"}},
+		// The preset's settings are kept alongside the file's settings
+		{"rust-analyzer.inlayHints.lifetimeElisionHints.enable", "skip_trivial"},
+		// Keys inside values are not split on dots
+		{"yaml.schemas", map[string]any{"https://json.schemastore.org/github-workflow.json": ".github/workflows/*"}},
+		{"unknown.section", map[string]any{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.section, func(t *testing.T) {
+			if got := client.ConfigurationSection(tt.section); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadSettings_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SettingsFileName)
+	if err := os.WriteFile(path, []byte(`["not", "an", "object"]`), 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	if _, err := LoadSettings(path); err == nil {
+		t.Error("expected an error for a settings file that is not an object")
+	}
+}
+
+func TestHandleWorkspaceConfiguration(t *testing.T) {
+	client := &Client{settings: map[string]any{"gopls": map[string]any{"gofumpt": true}}}
+
+	params := json.RawMessage(`{"items": [{"section": "gopls"}, {"section": "pylsp"}]}`)
+	result, err := HandleWorkspaceConfiguration(client, params)
+	if err != nil {
+		t.Fatalf("HandleWorkspaceConfiguration failed: %v", err)
+	}
+
+	expected := []any{map[string]any{"gofumpt": true}, map[string]any{}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %#v, got %#v", expected, result)
+	}
+}
+
+func TestSetSettings_PushesOnceInitialized(t *testing.T) {
+	reader, writer := io.Pipe()
+	client := &Client{writer: writer}
+
+	messages := make(chan *Message, 1)
+	go func() {
+		r := bufio.NewReader(reader)
+		for {
+			msg, err := ReadMessage(r)
+			if err != nil {
+				return
+			}
+			messages <- msg
+		}
+	}()
+
+	ctx := context.Background()
+
+	// Before initialization the settings are only stored
+	if err := client.SetSettings(ctx, map[string]any{"gopls": map[string]any{"gofumpt": false}}); err != nil {
+		t.Fatalf("SetSettings failed: %v", err)
+	}
+	select {
+	case msg := <-messages:
+		t.Fatalf("expected nothing to be sent before initialization, got %s", msg.Method)
+	default:
+	}
+
+	client.initialized.Store(true)
+	if err := client.SetSettings(ctx, map[string]any{"gopls": map[string]any{"gofumpt": true}}); err != nil {
+		t.Fatalf("SetSettings failed: %v", err)
+	}
+
+	msg := <-messages
+	if msg.Method != "workspace/didChangeConfiguration" {
+		t.Fatalf("expected workspace/didChangeConfiguration, got %s", msg.Method)
+	}
+	var params struct {
+		Settings map[string]any `json:"settings"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		t.Fatalf("failed to decode params: %v", err)
+	}
+	if !reflect.DeepEqual(params.Settings["gopls"], map[string]any{"gofumpt": true}) {
+		t.Errorf("expected the new gopls settings to be pushed, got %#v", params.Settings)
+	}
+
+	_ = writer.Close()
+}
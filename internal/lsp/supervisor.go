@@ -0,0 +1,165 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+const (
+	// Delay before the first restart attempt after a crash
+	minRestartBackoff = 500 * time.Millisecond
+	// Longest delay between restart attempts
+	maxRestartBackoff = 30 * time.Second
+	// A server that stays up this long is considered healthy again, so the
+	// next crash starts over from the shortest backoff
+	stableUptime = time.Minute
+	// Consecutive failed restart attempts after which the server is given up on
+	maxRestartAttempts = 5
+)
+
+// Supervisor restarts the language server when its process exits without
+// Close having been called. Requests in flight when the server exits fail
+// with ErrServerExited; the Client stays usable across restarts. If the
+// server cannot be restarted, the client is marked failed and requests fail
+// with the reason.
+type Supervisor struct {
+	client       *Client
+	workspaceDir string
+
+	// Called after the server exits and before the new one is initialized,
+	// so state tied to the old process (such as file watcher registrations)
+	// can be dropped
+	beforeRestart func()
+
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxAttempts int
+
+	restarts atomic.Int32
+}
+
+// NewSupervisor creates a supervisor for client. beforeRestart may be nil.
+func NewSupervisor(client *Client, workspaceDir string, beforeRestart func()) *Supervisor {
+	return &Supervisor{
+		client:        client,
+		workspaceDir:  workspaceDir,
+		beforeRestart: beforeRestart,
+		minBackoff:    minRestartBackoff,
+		maxBackoff:    maxRestartBackoff,
+		maxAttempts:   maxRestartAttempts,
+	}
+}
+
+// Restarts returns the number of times the server has been restarted
+func (s *Supervisor) Restarts() int {
+	return int(s.restarts.Load())
+}
+
+// Run watches the server and restarts it each time it exits, until ctx is
+// done, the client is closed or the server cannot be restarted
+func (s *Supervisor) Run(ctx context.Context) {
+	backoff := s.minBackoff
+	startedAt := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.client.Exited():
+		}
+		if ctx.Err() != nil || s.client.closing.Load() {
+			return
+		}
+
+		exitErr := s.client.ExitError()
+		lspLogger.Error("Language server exited unexpectedly (%v), restarting", exitErr)
+
+		if time.Since(startedAt) >= stableUptime {
+			backoff = s.minBackoff
+		}
+
+		paths := s.client.takeOpenFiles()
+		if s.beforeRestart != nil {
+			s.beforeRestart()
+		}
+
+		var err error
+		for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, s.maxBackoff)
+
+			err = s.client.restart(ctx, s.workspaceDir, paths)
+			if err == nil {
+				break
+			}
+			lspLogger.Error("Restart attempt %d of %d failed: %v", attempt, s.maxAttempts, err)
+		}
+		if err != nil {
+			failure := fmt.Errorf("%w (%v) and could not be restarted after %d attempts: %w", ErrServerExited, exitErr, s.maxAttempts, err)
+			s.client.fail(failure)
+			lspLogger.Error("Giving up on %s: %v", s.client.Name(), failure)
+			return
+		}
+
+		startedAt = time.Now()
+		count := s.restarts.Add(1)
+		lspLogger.Info("Language server restarted (%d restarts so far), reopened %d files", count, len(paths))
+	}
+}
+
+// takeOpenFiles forgets the files the previous server had open, along with
+// their diagnostics, and returns their paths so they can be reopened. The
+// least recently used come first, so they are the first evicted again.
+func (c *Client) takeOpenFiles() []string {
+	c.openFilesMu.Lock()
+	paths := c.openFilePaths()
+	c.openFiles = make(map[string]*OpenFileInfo)
+	c.openFilesMu.Unlock()
+
+	c.diagnosticsMu.Lock()
+	c.diagnostics = make(map[protocol.DocumentUri][]protocol.Diagnostic)
+	c.diagnosticsMu.Unlock()
+
+	return paths
+}
+
+// restart starts a new server process, initializes it and reopens paths.
+// If initialization fails the new process is killed.
+func (c *Client) restart(ctx context.Context, workspaceDir string, paths []string) error {
+	c.resetProgress()
+
+	if err := c.start(); err != nil {
+		return err
+	}
+
+	if _, err := c.InitializeLSPClient(ctx, workspaceDir); err != nil {
+		c.processMu.RLock()
+		conn, exited := c.conn, c.exited
+		c.processMu.RUnlock()
+		if err := conn.Kill(); err != nil {
+			lspLogger.Error("Failed to kill process: %v", err)
+		}
+		<-exited
+		return fmt.Errorf("initialize failed: %w", err)
+	}
+
+	if err := c.WaitForServerReady(ctx); err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := c.OpenFile(ctx, path); err != nil {
+			lspLogger.Warn("Failed to reopen %s after restart: %v", path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// TestHelperFakeServer is not a real test. It is run as a subprocess by the
+// tests below to act as a minimal language server: it answers initialize,
+// picking the client's preferred position encoding, answers shutdown, and
+// exits when asked to crash. With LSP_FAKE_SERVER=broken it exits at once.
+func TestHelperFakeServer(t *testing.T) {
+	switch os.Getenv("LSP_FAKE_SERVER") {
+	case "1":
+	case "broken":
+		os.Exit(4)
+	default:
+		return
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		msg, err := ReadMessage(reader)
+		if err != nil {
+			os.Exit(0)
+		}
+		if msg.ID == nil {
+			continue
+		}
+
+		switch msg.Method {
+		case "test/crash":
+			os.Exit(3)
+		case "test/hang":
+			continue
+		}
+
+		response := &Message{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage(`null`)}
+		if msg.Method == "initialize" {
+			response.Result = initializeResult(msg.Params)
+		}
+		if err := WriteMessage(os.Stdout, response); err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// initializeResult answers initialize with the first position encoding the
+// client offers
+func initializeResult(params json.RawMessage) json.RawMessage {
+	var initParams protocol.InitializeParams
+	_ = json.Unmarshal(params, &initParams)
+
+	capabilities := protocol.ServerCapabilities{}
+	if general := initParams.Capabilities.General; general != nil && len(general.PositionEncodings) > 0 {
+		capabilities.PositionEncoding = &general.PositionEncodings[0]
+	}
+	result, _ := json.Marshal(protocol.InitializeResult{Capabilities: capabilities})
+	return result
+}
+
+func newFakeServerClient(t *testing.T) *Client {
+	t.Setenv("LSP_FAKE_SERVER", "1")
+
+	client, err := NewClient(os.Args[0], "-test.run=^TestHelperFakeServer$")
+	if err != nil {
+		t.Fatalf("failed to start fake server: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestCall_ServerExits_FailsPendingRequest(t *testing.T) {
+	client := newFakeServerClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.Call(ctx, "test/hang", nil, nil)
+	}()
+
+	// Give the hanging request time to reach the server before crashing it
+	time.Sleep(100 * time.Millisecond)
+	if err := client.Call(ctx, "test/crash", nil, nil); !errors.Is(err, ErrServerExited) {
+		t.Fatalf("expected ErrServerExited from the crashing request, got %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrServerExited) {
+			t.Fatalf("expected ErrServerExited for the pending request, got %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("pending request was not failed when the server exited")
+	}
+
+	// Later requests fail immediately instead of writing to a dead process
+	if err := client.Call(ctx, "test/echo", nil, nil); !errors.Is(err, ErrServerExited) {
+		t.Errorf("expected ErrServerExited after the server exited, got %v", err)
+	}
+}
+
+func TestSupervisor_RestartsCrashedServer(t *testing.T) {
+	client := newFakeServerClient(t)
+	workspaceDir := t.TempDir()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := client.InitializeLSPClient(ctx, workspaceDir); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	if got := client.PositionEncoding(); got != protocol.UTF8 {
+		t.Errorf("expected the server to pick utf-8 positions, got %v", got)
+	}
+
+	filePath := filepath.Join(workspaceDir, "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+
+	resets := 0
+	supervisor := NewSupervisor(client, workspaceDir, func() { resets++ })
+	supervisor.minBackoff = 10 * time.Millisecond
+	go supervisor.Run(ctx)
+
+	oldExited := client.Exited()
+	if err := client.Call(ctx, "test/crash", nil, nil); !errors.Is(err, ErrServerExited) {
+		t.Fatalf("expected ErrServerExited, got %v", err)
+	}
+	<-oldExited
+
+	for supervisor.Restarts() == 0 {
+		select {
+		case <-ctx.Done():
+			t.Fatal("server was not restarted")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if resets != 1 {
+		t.Errorf("expected beforeRestart to be called once, got %d", resets)
+	}
+	if !client.IsFileOpen(filePath) {
+		t.Errorf("expected %s to be reopened after the restart", filePath)
+	}
+	if err := client.Call(ctx, "test/echo", nil, nil); err != nil {
+		t.Errorf("expected the restarted server to answer requests, got %v", err)
+	}
+}
+
+func TestSupervisor_GivesUpAfterFailedRestarts(t *testing.T) {
+	client := newFakeServerClient(t)
+	workspaceDir := t.TempDir()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := client.InitializeLSPClient(ctx, workspaceDir); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	supervisor := NewSupervisor(client, workspaceDir, nil)
+	supervisor.minBackoff = 10 * time.Millisecond
+	supervisor.maxAttempts = 3
+	done := make(chan struct{})
+	go func() {
+		supervisor.Run(ctx)
+		close(done)
+	}()
+
+	// Every server started from now on exits before initializing
+	t.Setenv("LSP_FAKE_SERVER", "broken")
+	if err := client.Call(ctx, "test/crash", nil, nil); !errors.Is(err, ErrServerExited) {
+		t.Fatalf("expected ErrServerExited, got %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("supervisor kept restarting a server that cannot start")
+	}
+	if supervisor.Restarts() != 0 {
+		t.Errorf("expected no successful restarts, got %d", supervisor.Restarts())
+	}
+
+	failure := client.Failure()
+	if failure == nil {
+		t.Fatal("expected the client to be marked failed")
+	}
+	err := client.Call(ctx, "test/echo", nil, nil)
+	if !errors.Is(err, ErrServerExited) || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("expected requests to fail with the reason the server was given up on, got %v", err)
+	}
+}
+
+func TestSupervisor_IgnoresClose(t *testing.T) {
+	client := newFakeServerClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	supervisor := NewSupervisor(client, t.TempDir(), nil)
+	supervisor.minBackoff = 10 * time.Millisecond
+	done := make(chan struct{})
+	go func() {
+		supervisor.Run(ctx)
+		close(done)
+	}()
+
+	_ = client.Close()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("supervisor did not stop after Close")
+	}
+	if supervisor.Restarts() != 0 {
+		t.Errorf("expected no restarts after Close, got %d", supervisor.Restarts())
+	}
+}
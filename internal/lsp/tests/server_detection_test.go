@@ -3,6 +3,7 @@ package tests
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/vector67/mcp-language-server/internal/lsp"
@@ -135,3 +136,152 @@ func TestDetectServer(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectRoots(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"backend/go.mod",
+		"web/package.json",
+		"web/node_modules/left-pad/package.json",
+		"tools/lint/go.mod",
+		"build/out/Cargo.toml",
+		".cache/go.mod",
+		"services/api/python/pyproject.toml",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, []byte{}, 0644); err != nil {
+			t.Fatalf("failed to create marker file %s: %v", file, err)
+		}
+	}
+
+	// Stands in for .gitignore
+	skip := func(path string, isDir bool) bool {
+		return path == filepath.Join(dir, "build")
+	}
+
+	roots, err := lsp.BuiltinPresets().DetectRoots(dir, 2, skip)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, root := range roots {
+		rel, _ := filepath.Rel(dir, root.Dir)
+		got[rel] = root.Preset.Name
+	}
+	want := map[string]string{
+		"backend":    "gopls",
+		"web":        "typescript-language-server",
+		"tools/lint": "gopls",
+	}
+	if len(got) != len(want) {
+		t.Errorf("roots = %v, want %v", got, want)
+	}
+	for rel, name := range want {
+		if got[rel] != name {
+			t.Errorf("root %s = %q, want %q", rel, got[rel], name)
+		}
+	}
+
+	// One server per kind of project, each handling its languages
+	configs := lsp.ServerConfigsForRoots(roots)
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 servers, got %d", len(configs))
+	}
+	gopls := configs[0]
+	if gopls.Command != "gopls" || len(gopls.Roots) != 2 || len(gopls.Languages) == 0 {
+		t.Errorf("expected gopls for backend and tools/lint handling Go files, got %+v", gopls)
+	}
+
+	if _, err := lsp.BuiltinPresets().DetectRoots(t.TempDir(), 2, nil); err == nil {
+		t.Error("expected an error for a workspace without projects")
+	}
+}
+
+func TestServerConfigsForRoots_SingleProject(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "backend"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "backend", "go.mod"), []byte{}, 0644); err != nil {
+		t.Fatalf("failed to create marker file: %v", err)
+	}
+
+	// A marker deeper than the depth is not found
+	if _, err := lsp.BuiltinPresets().DetectRoots(dir, 0, nil); err == nil {
+		t.Error("expected no projects at depth 0")
+	}
+
+	roots, err := lsp.BuiltinPresets().DetectRoots(dir, 1, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	configs := lsp.ServerConfigsForRoots(roots)
+	if len(configs) != 1 {
+		t.Fatalf("expected 1 server, got %d", len(configs))
+	}
+	// The only server handles every file, rooted at the project
+	if len(configs[0].Languages) != 0 || len(configs[0].Roots) != 1 || configs[0].Roots[0] != filepath.Join(dir, "backend") {
+		t.Errorf("expected a default gopls rooted at backend, got %+v", configs[0])
+	}
+}
+
+func TestDetectRoots_SeveralProjectsInOneDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for _, marker := range []string{"go.mod", "go.sum", "package.json"} {
+		if err := os.WriteFile(filepath.Join(dir, marker), []byte{}, 0644); err != nil {
+			t.Fatalf("failed to create marker file %s: %v", marker, err)
+		}
+	}
+
+	roots, err := lsp.BuiltinPresets().DetectRoots(dir, 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// One root per preset, however many of its markers are found
+	var got []string
+	for _, root := range roots {
+		if root.Dir != dir {
+			t.Errorf("expected a root at %s, got %s", dir, root.Dir)
+		}
+		got = append(got, root.Preset.Name)
+	}
+	if len(got) != 2 || got[0] != "gopls" || got[1] != "typescript-language-server" {
+		t.Errorf("expected gopls and typescript-language-server, got %v", got)
+	}
+
+	if configs := lsp.ServerConfigsForRoots(roots); len(configs) != 2 {
+		t.Errorf("expected 2 servers, got %d", len(configs))
+	}
+}
+
+func TestServerConfigsForRoots_NestedRoots(t *testing.T) {
+	presets := lsp.BuiltinPresets()
+	gopls, typescript := presets.Find("gopls"), presets.Find("typescript-language-server")
+	dir := t.TempDir()
+	roots := []lsp.ProjectRoot{
+		{Dir: filepath.Join(dir, "services", "api"), Preset: gopls},
+		{Dir: filepath.Join(dir, "services"), Preset: gopls},
+		{Dir: filepath.Join(dir, "services", "api", "tools"), Preset: gopls},
+		{Dir: filepath.Join(dir, "services-extra"), Preset: gopls},
+		{Dir: filepath.Join(dir, "services", "web"), Preset: typescript},
+	}
+
+	configs := lsp.ServerConfigsForRoots(roots)
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 servers, got %d", len(configs))
+	}
+	// Roots inside another of the same preset are dropped, but only that
+	// preset's: services/web is kept for typescript-language-server
+	want := []string{filepath.Join(dir, "services"), filepath.Join(dir, "services-extra")}
+	if !reflect.DeepEqual(configs[0].Roots, want) {
+		t.Errorf("gopls roots = %v, want %v", configs[0].Roots, want)
+	}
+	if len(configs[1].Roots) != 1 || configs[1].Roots[0] != filepath.Join(dir, "services", "web") {
+		t.Errorf("typescript-language-server roots = %v, want services/web", configs[1].Roots)
+	}
+}
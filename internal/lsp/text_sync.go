@@ -0,0 +1,130 @@
+package lsp
+
+import (
+	"unicode/utf8"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+	"github.com/vector67/mcp-language-server/internal/utilities"
+)
+
+// textDocumentSyncKind returns how the server wants document changes sent,
+// from either form of the textDocumentSync capability
+func (c *Client) textDocumentSyncKind() protocol.TextDocumentSyncKind {
+	switch v := c.ServerCapabilities().TextDocumentSync.(type) {
+	case float64:
+		return protocol.TextDocumentSyncKind(v)
+	case protocol.TextDocumentSyncKind:
+		return v
+	case map[string]any:
+		change, _ := v["change"].(float64)
+		return protocol.TextDocumentSyncKind(change)
+	case protocol.TextDocumentSyncOptions:
+		return v.Change
+	case *protocol.TextDocumentSyncOptions:
+		if v != nil {
+			return v.Change
+		}
+	}
+	return protocol.Full
+}
+
+// saveOptions reports whether the server wants textDocument/didSave
+// notifications, and whether they should include the saved text
+func (c *Client) saveOptions() (send bool, includeText bool) {
+	switch v := c.ServerCapabilities().TextDocumentSync.(type) {
+	case map[string]any:
+		switch save := v["save"].(type) {
+		case bool:
+			return save, false
+		case map[string]any:
+			includeText, _ := save["includeText"].(bool)
+			return true, includeText
+		}
+	case protocol.TextDocumentSyncOptions:
+		return v.Save != nil, v.Save != nil && v.Save.IncludeText
+	case *protocol.TextDocumentSyncOptions:
+		if v != nil && v.Save != nil {
+			return true, v.Save.IncludeText
+		}
+	}
+	return false, false
+}
+
+// incrementalChange describes the edit from oldText to newText as a single
+// range replacement covering everything between their common prefix and
+// common suffix, with positions counted in code units of encoding. It returns
+// false if the texts are equal.
+func incrementalChange(oldText, newText string, encoding protocol.PositionEncodingKind) (protocol.TextDocumentContentChangePartial, bool) {
+	if oldText == newText {
+		return protocol.TextDocumentContentChangePartial{}, false
+	}
+
+	maxCommon := min(len(oldText), len(newText))
+
+	prefix := 0
+	for prefix < maxCommon && oldText[prefix] == newText[prefix] {
+		prefix++
+	}
+	prefix = alignToBoundary(oldText, prefix)
+
+	suffix := 0
+	for suffix < maxCommon-prefix && oldText[len(oldText)-1-suffix] == newText[len(newText)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && !isBoundary(oldText, len(oldText)-suffix) {
+		suffix--
+	}
+
+	start := offsetToPosition(oldText, prefix, encoding)
+	end := offsetToPosition(oldText, len(oldText)-suffix, encoding)
+	return protocol.TextDocumentContentChangePartial{
+		Range: &protocol.Range{Start: start, End: end},
+		Text:  newText[prefix : len(newText)-suffix],
+	}, true
+}
+
+// isBoundary reports whether offset is a valid place to split text for a
+// position: not inside a UTF-8 sequence and not between \r and \n
+func isBoundary(text string, offset int) bool {
+	if offset <= 0 || offset >= len(text) {
+		return true
+	}
+	if !utf8.RuneStart(text[offset]) {
+		return false
+	}
+	return !(text[offset-1] == '\r' && text[offset] == '\n')
+}
+
+// alignToBoundary moves offset back to the nearest boundary
+func alignToBoundary(text string, offset int) int {
+	for offset > 0 && !isBoundary(text, offset) {
+		offset--
+	}
+	return offset
+}
+
+// offsetToPosition converts a byte offset in text to a position with the
+// character counted in code units of encoding. \n, \r\n and \r all end a
+// line.
+func offsetToPosition(text string, offset int, encoding protocol.PositionEncodingKind) protocol.Position {
+	var line uint32
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		switch text[i] {
+		case '\n':
+			line++
+			lineStart = i + 1
+		case '\r':
+			// \r\n is a single line break, counted at the \n
+			if i+1 < len(text) && text[i+1] == '\n' {
+				continue
+			}
+			line++
+			lineStart = i + 1
+		}
+	}
+	return protocol.Position{
+		Line:      line,
+		Character: utilities.TextLength(text[lineStart:offset], encoding),
+	}
+}
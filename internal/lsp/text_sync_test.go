@@ -0,0 +1,248 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+	"github.com/vector67/mcp-language-server/internal/utilities"
+)
+
+// positionToOffset converts a position in the given encoding to a byte
+// offset, treating \n, \r\n and \r as line breaks
+func positionToOffset(t *testing.T, text string, pos protocol.Position, encoding protocol.PositionEncodingKind) int {
+	t.Helper()
+	var line, character uint32
+	for i, r := range text {
+		if line == pos.Line && character == pos.Character {
+			return i
+		}
+		switch {
+		case r == '\r' && i+1 < len(text) && text[i+1] == '\n':
+		case r == '\n' || r == '\r':
+			line++
+			character = 0
+		default:
+			character += utilities.TextLength(string(r), encoding)
+		}
+	}
+	if line == pos.Line && character == pos.Character {
+		return len(text)
+	}
+	t.Fatalf("position %+v is not in %q", pos, text)
+	return 0
+}
+
+func TestIncrementalChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldText   string
+		newText   string
+		encoding  protocol.PositionEncodingKind
+		wantRange protocol.Range
+		wantText  string
+	}{
+		{
+			name:      "insert in middle",
+			oldText:   "package main\n\nfunc main() {}\n",
+			newText:   "package main\n\nfunc main() { run() }\n",
+			wantRange: protocol.Range{Start: protocol.Position{Line: 2, Character: 13}, End: protocol.Position{Line: 2, Character: 13}},
+			wantText:  " run() ",
+		},
+		{
+			name:      "delete lines",
+			oldText:   "a\nb\nc\nd\n",
+			newText:   "a\nd\n",
+			wantRange: protocol.Range{Start: protocol.Position{Line: 1, Character: 0}, End: protocol.Position{Line: 3, Character: 0}},
+			wantText:  "",
+		},
+		{
+			name:      "append at end",
+			oldText:   "x := 1",
+			newText:   "x := 1\ny := 2\n",
+			wantRange: protocol.Range{Start: protocol.Position{Line: 0, Character: 6}, End: protocol.Position{Line: 0, Character: 6}},
+			wantText:  "\ny := 2\n",
+		},
+		{
+			name:    "multi-byte characters sharing a leading byte",
+			oldText: "s := \"café\" // 🙂\n",
+			newText: "s := \"cafè\" // 🙂\n",
+			// é and è share their first UTF-8 byte, which must not be split
+			wantRange: protocol.Range{Start: protocol.Position{Line: 0, Character: 9}, End: protocol.Position{Line: 0, Character: 10}},
+			wantText:  "è",
+		},
+		{
+			name:      "utf-16 columns after astral characters",
+			oldText:   "🙂🙂 a\n",
+			newText:   "🙂🙂 b\n",
+			encoding:  protocol.UTF16,
+			wantRange: protocol.Range{Start: protocol.Position{Line: 0, Character: 5}, End: protocol.Position{Line: 0, Character: 6}},
+			wantText:  "b",
+		},
+		{
+			name:      "utf-8 columns after astral characters",
+			oldText:   "🙂🙂 a\n",
+			newText:   "🙂🙂 b\n",
+			encoding:  protocol.UTF8,
+			wantRange: protocol.Range{Start: protocol.Position{Line: 0, Character: 9}, End: protocol.Position{Line: 0, Character: 10}},
+			wantText:  "b",
+		},
+		{
+			name:      "utf-32 columns after astral characters",
+			oldText:   "🙂🙂 a\n",
+			newText:   "🙂🙂 b\n",
+			encoding:  protocol.UTF32,
+			wantRange: protocol.Range{Start: protocol.Position{Line: 0, Character: 3}, End: protocol.Position{Line: 0, Character: 4}},
+			wantText:  "b",
+		},
+		{
+			name:      "crlf line breaks are not split",
+			oldText:   "one\r\ntwo\r\n",
+			newText:   "one\r\n\r\ntwo\r\n",
+			wantRange: protocol.Range{Start: protocol.Position{Line: 1, Character: 0}, End: protocol.Position{Line: 1, Character: 0}},
+			wantText:  "\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding := tt.encoding
+			if encoding == "" {
+				encoding = protocol.UTF16
+			}
+			change, ok := incrementalChange(tt.oldText, tt.newText, encoding)
+			if !ok {
+				t.Fatal("expected a change")
+			}
+			if *change.Range != tt.wantRange {
+				t.Errorf("expected range %+v, got %+v", tt.wantRange, *change.Range)
+			}
+			if change.Text != tt.wantText {
+				t.Errorf("expected text %q, got %q", tt.wantText, change.Text)
+			}
+
+			// Applying the change to the old text must give the new text
+			start := positionToOffset(t, tt.oldText, change.Range.Start, encoding)
+			end := positionToOffset(t, tt.oldText, change.Range.End, encoding)
+			if got := tt.oldText[:start] + change.Text + tt.oldText[end:]; got != tt.newText {
+				t.Errorf("applying the change gave %q, want %q", got, tt.newText)
+			}
+		})
+	}
+
+	if _, ok := incrementalChange("same", "same", protocol.UTF16); ok {
+		t.Error("expected no change for equal texts")
+	}
+}
+
+func TestTextDocumentSyncKind(t *testing.T) {
+	tests := []struct {
+		sync     string
+		expected protocol.TextDocumentSyncKind
+	}{
+		{`2`, protocol.Incremental},
+		{`1`, protocol.Full},
+		{`{"openClose": true, "change": 2}`, protocol.Incremental},
+		{`{"openClose": true}`, protocol.TextDocumentSyncKind(0)},
+	}
+	for _, tt := range tests {
+		var caps protocol.ServerCapabilities
+		if err := json.Unmarshal([]byte(`{"textDocumentSync": `+tt.sync+`}`), &caps); err != nil {
+			t.Fatalf("failed to decode capabilities: %v", err)
+		}
+		client := &Client{serverCapabilities: caps}
+		if got := client.textDocumentSyncKind(); got != tt.expected {
+			t.Errorf("textDocumentSync %s: expected %v, got %v", tt.sync, tt.expected, got)
+		}
+	}
+}
+
+func TestSaveOptions(t *testing.T) {
+	tests := []struct {
+		sync        string
+		send        bool
+		includeText bool
+	}{
+		{`2`, false, false},
+		{`{"change": 2}`, false, false},
+		{`{"change": 2, "save": true}`, true, false},
+		{`{"change": 2, "save": false}`, false, false},
+		{`{"change": 2, "save": {"includeText": true}}`, true, true},
+		{`{"change": 2, "save": {}}`, true, false},
+	}
+	for _, tt := range tests {
+		var caps protocol.ServerCapabilities
+		if err := json.Unmarshal([]byte(`{"textDocumentSync": `+tt.sync+`}`), &caps); err != nil {
+			t.Fatalf("failed to decode capabilities: %v", err)
+		}
+		client := &Client{serverCapabilities: caps}
+		send, includeText := client.saveOptions()
+		if send != tt.send || includeText != tt.includeText {
+			t.Errorf("textDocumentSync %s: expected (%v, %v), got (%v, %v)", tt.sync, tt.send, tt.includeText, send, includeText)
+		}
+	}
+}
+
+func TestNotifyContent_SendsIncrementalChanges(t *testing.T) {
+	reader, writer := io.Pipe()
+	client := &Client{
+		writer:             writer,
+		openFiles:          make(map[string]*OpenFileInfo),
+		serverCapabilities: protocol.ServerCapabilities{TextDocumentSync: float64(protocol.Incremental)},
+	}
+	client.openFiles["file:///main.go"] = &OpenFileInfo{Version: 1, URI: "file:///main.go", Text: "a\nb\n"}
+
+	messages := make(chan *Message, 2)
+	go func() {
+		r := bufio.NewReader(reader)
+		for {
+			msg, err := ReadMessage(r)
+			if err != nil {
+				return
+			}
+			messages <- msg
+		}
+	}()
+
+	ctx := context.Background()
+	if err := client.NotifyContent(ctx, "/main.go", "a\nB\n"); err != nil {
+		t.Fatalf("NotifyContent failed: %v", err)
+	}
+
+	msg := <-messages
+	var params struct {
+		TextDocument   protocol.VersionedTextDocumentIdentifier    `json:"textDocument"`
+		ContentChanges []protocol.TextDocumentContentChangePartial `json:"contentChanges"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		t.Fatalf("failed to decode didChange: %v", err)
+	}
+	if params.TextDocument.Version != 2 {
+		t.Errorf("expected version 2, got %d", params.TextDocument.Version)
+	}
+	if len(params.ContentChanges) != 1 || params.ContentChanges[0].Range == nil {
+		t.Fatalf("expected one range change, got %s", msg.Params)
+	}
+	wantRange := protocol.Range{Start: protocol.Position{Line: 1}, End: protocol.Position{Line: 1, Character: 1}}
+	if *params.ContentChanges[0].Range != wantRange || params.ContentChanges[0].Text != "B" {
+		t.Errorf("unexpected change %s", msg.Params)
+	}
+
+	// Unchanged content sends nothing and keeps the version
+	if err := client.NotifyContent(ctx, "/main.go", "a\nB\n"); err != nil {
+		t.Fatalf("NotifyContent failed: %v", err)
+	}
+	select {
+	case msg := <-messages:
+		t.Errorf("expected no change to be sent, got %s", msg.Params)
+	default:
+	}
+	if info := client.openFiles["file:///main.go"]; info.Version != 2 || info.Text != "a\nB\n" {
+		t.Errorf("expected version 2 with the new text, got %d %q", info.Version, info.Text)
+	}
+
+	_ = writer.Close()
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"strings"
 
 	"github.com/vector67/mcp-language-server/internal/logging"
@@ -21,6 +22,7 @@ var processLogger = logging.NewLogger(logging.LSPProcess)
 var (
 	ErrContentModified = errors.New("content modified")
 	ErrServerCancelled = errors.New("server cancelled")
+	ErrServerExited    = errors.New("language server exited")
 )
 
 // WriteMessage writes an LSP message to the given writer
@@ -101,14 +103,38 @@ func ReadMessage(r *bufio.Reader) (*Message, error) {
 	return &msg, nil
 }
 
-// handleMessages reads and dispatches messages in a loop
-func (c *Client) handleMessages() {
+// send writes a message to the current server process. It returns the
+// process's exited channel so callers can stop waiting if it dies.
+func (c *Client) send(msg *Message) (<-chan struct{}, error) {
+	c.processMu.RLock()
+	defer c.processMu.RUnlock()
+
+	select {
+	case <-c.exited:
+		if c.failure != nil {
+			return c.exited, c.failure
+		}
+		return c.exited, ErrServerExited
+	default:
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.exited, WriteMessage(c.writer, msg)
+}
+
+// handleMessages reads and dispatches messages in a loop until the server's
+// output ends
+func (c *Client) handleMessages(stdout *bufio.Reader) {
 	for {
-		msg, err := ReadMessage(c.stdout)
+		msg, err := ReadMessage(stdout)
 		if err != nil {
-			// Check if this is due to normal shutdown (EOF when closing connection)
+			// Check if this is due to normal shutdown (EOF when closing connection,
+			// or a socket the client closed itself)
 			if strings.Contains(err.Error(), "EOF") {
 				lspLogger.Info("LSP connection closed (EOF)")
+			} else if errors.Is(err, net.ErrClosed) && c.closing.Load() {
+				lspLogger.Info("LSP connection closed")
 			} else {
 				lspLogger.Error("Error reading message: %v", err)
 			}
@@ -157,13 +183,19 @@ func (c *Client) handleMessages() {
 			}
 
 			// Send response back to server
-			if err := WriteMessage(c.stdin, response); err != nil {
+			if _, err := c.send(response); err != nil {
 				lspLogger.Error("Error sending response to server: %v", err)
 			}
 
 			continue
 		}
 
+		// Progress is tracked in order, so it is not handed to a goroutine
+		if msg.Method == "$/progress" && (msg.ID == nil || msg.ID.Value == nil) {
+			c.handleProgress(msg.Params)
+			continue
+		}
+
 		// Handle notification (has Method but no ID)
 		if msg.Method != "" && (msg.ID == nil || msg.ID.Value == nil) {
 			c.notificationMu.RLock()
@@ -209,6 +241,11 @@ func (c *Client) Call(ctx context.Context, method string, params any, result any
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// Keep the document open until the server has answered
+	if uri := requestDocument(msg.Params); uri != "" {
+		defer c.pinURI(uri)()
+	}
+
 	// Create response channel
 	ch := make(chan *Message, 1)
 	// Convert ID to string for map lookup
@@ -224,14 +261,35 @@ func (c *Client) Call(ctx context.Context, method string, params any, result any
 	}()
 
 	// Send request
-	if err := WriteMessage(c.stdin, msg); err != nil {
+	exited, err := c.send(msg)
+	if errors.Is(err, ErrServerExited) {
+		return fmt.Errorf("%s failed: %w", method, err)
+	} else if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 
 	lspLogger.Debug("Waiting for response to request ID: %v", msg.ID)
 
-	// Wait for response
-	resp := <-ch
+	// Wait for response, giving up if the caller is cancelled
+	var resp *Message
+	select {
+	case resp = <-ch:
+	case <-exited:
+		// The response may have been delivered just before the output ended
+		select {
+		case resp = <-ch:
+		default:
+			return fmt.Errorf("%s failed: %w before responding", method, ErrServerExited)
+		}
+	case <-ctx.Done():
+		lspLogger.Debug("Request cancelled: method=%s id=%v: %v", method, msg.ID, ctx.Err())
+		// Let the server stop working on it; the deferred cleanup drops the
+		// handler so a late response is ignored
+		if err := c.Notify(context.Background(), "$/cancelRequest", protocol.CancelParams{ID: id}); err != nil {
+			lspLogger.Warn("Failed to cancel request %v: %v", msg.ID, err)
+		}
+		return ctx.Err()
+	}
 
 	lspLogger.Debug("Received response for request ID: %v", msg.ID)
 
@@ -272,7 +330,7 @@ func (c *Client) Notify(ctx context.Context, method string, params any) error {
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
 
-	if err := WriteMessage(c.stdin, msg); err != nil {
+	if _, err := c.send(msg); err != nil {
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
 
@@ -0,0 +1,76 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCall_CancelledContext_SendsCancelRequest(t *testing.T) {
+	reader, writer := io.Pipe()
+	client := &Client{
+		writer:   writer,
+		handlers: make(map[string]chan *Message),
+	}
+
+	// Collect what the client writes to the server
+	messages := make(chan *Message, 2)
+	go func() {
+		r := bufio.NewReader(reader)
+		for {
+			msg, err := ReadMessage(r)
+			if err != nil {
+				return
+			}
+			messages <- msg
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.Call(ctx, "textDocument/hover", map[string]any{}, nil)
+	}()
+
+	request := <-messages
+	if request.Method != "textDocument/hover" {
+		t.Fatalf("expected the hover request first, got %q", request.Method)
+	}
+
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Call did not return after its context was cancelled")
+	}
+
+	cancelMsg := <-messages
+	if cancelMsg.Method != "$/cancelRequest" {
+		t.Fatalf("expected $/cancelRequest, got %q", cancelMsg.Method)
+	}
+	var params struct {
+		ID int32 `json:"id"`
+	}
+	if err := json.Unmarshal(cancelMsg.Params, &params); err != nil {
+		t.Fatalf("failed to decode cancel params: %v", err)
+	}
+	if params.ID != request.ID.Value {
+		t.Errorf("expected cancel for id %v, got %v", request.ID.Value, params.ID)
+	}
+
+	client.handlersMu.RLock()
+	defer client.handlersMu.RUnlock()
+	if len(client.handlers) != 0 {
+		t.Errorf("expected the response handler to be removed, got %d", len(client.handlers))
+	}
+
+	_ = writer.Close()
+}
@@ -1,6 +1,10 @@
 package protocol
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
 
 // TextEditResult is an interface for types that represent workspace symbols
 type WorkspaceSymbolResult interface {
@@ -115,3 +119,138 @@ func (e Or_TextDocumentEdit_edits_Elem) AsTextEdit() (TextEdit, error) {
 		return TextEdit{}, fmt.Errorf("unknown text edit type: %T", e.Value)
 	}
 }
+
+// definitionLocations flattens the Definition, Declaration and link variants
+// returned by the definition-like requests into plain locations. For links the
+// selection range of the target is used, which points at the symbol's name.
+func definitionLocations(value any) ([]Location, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case Or_Definition:
+		return definitionLocations(v.Value)
+	case Or_Declaration:
+		return definitionLocations(v.Value)
+	case Location:
+		return []Location{v}, nil
+	case []Location:
+		return v, nil
+	case []LocationLink:
+		locations := make([]Location, len(v))
+		for i, link := range v {
+			locations[i] = Location{
+				URI:   link.TargetURI,
+				Range: link.TargetSelectionRange,
+			}
+		}
+		return locations, nil
+	default:
+		return nil, fmt.Errorf("unknown location type: %T", value)
+	}
+}
+
+// Locations converts the result of a textDocument/implementation request to a slice of Location
+func (r Or_Result_textDocument_implementation) Locations() ([]Location, error) {
+	return definitionLocations(r.Value)
+}
+
+// Locations converts the result of a textDocument/typeDefinition request to a slice of Location
+func (r Or_Result_textDocument_typeDefinition) Locations() ([]Location, error) {
+	return definitionLocations(r.Value)
+}
+
+// Locations converts the result of a textDocument/declaration request to a slice of Location
+func (r Or_Result_textDocument_declaration) Locations() ([]Location, error) {
+	return definitionLocations(r.Value)
+}
+
+// UnmarshalJSON decodes a parameter label given as [start, end] offsets into the signature label
+func (t *Tuple_ParameterInformation_label_Item1) UnmarshalJSON(x []byte) error {
+	var offsets [2]uint32
+	if err := json.Unmarshal(x, &offsets); err != nil {
+		return err
+	}
+	t.Fld0, t.Fld1 = offsets[0], offsets[1]
+	return nil
+}
+
+// MarshalJSON encodes a parameter label as [start, end] offsets
+func (t Tuple_ParameterInformation_label_Item1) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]uint32{t.Fld0, t.Fld1})
+}
+
+// List converts the result of a textDocument/completion request to a CompletionList.
+// Item defaults sent with the list are applied to the items that lack them.
+func (r Or_Result_textDocument_completion) List() (CompletionList, error) {
+	switch v := r.Value.(type) {
+	case nil:
+		return CompletionList{}, nil
+	case []CompletionItem:
+		return CompletionList{Items: v}, nil
+	case CompletionList:
+		if v.ItemDefaults != nil {
+			for i := range v.Items {
+				v.Items[i].applyDefaults(*v.ItemDefaults)
+			}
+		}
+		return v, nil
+	default:
+		return CompletionList{}, fmt.Errorf("unknown completion result type: %T", r.Value)
+	}
+}
+
+func (item *CompletionItem) applyDefaults(defaults CompletionItemDefaults) {
+	if item.TextEdit == nil && defaults.EditRange != nil {
+		newText := item.TextEditText
+		if newText == "" {
+			newText = item.Label
+		}
+		switch rng := defaults.EditRange.Value.(type) {
+		case Range:
+			item.TextEdit = &Or_CompletionItem_textEdit{Value: TextEdit{Range: rng, NewText: newText}}
+		case EditRangeWithInsertReplace:
+			item.TextEdit = &Or_CompletionItem_textEdit{Value: InsertReplaceEdit{NewText: newText, Insert: rng.Insert, Replace: rng.Replace}}
+		}
+	}
+	if item.InsertTextFormat == nil {
+		item.InsertTextFormat = defaults.InsertTextFormat
+	}
+	if item.InsertTextMode == nil {
+		item.InsertTextMode = defaults.InsertTextMode
+	}
+	if item.Data == nil {
+		item.Data = defaults.Data
+	}
+}
+
+// UnmarshalJSON decodes an inlay hint whose label is either a plain string or
+// an array of label parts. A string label becomes a single label part.
+func (h *InlayHint) UnmarshalJSON(x []byte) error {
+	type inlayHint InlayHint
+	var raw struct {
+		inlayHint
+		Label Or_InlayHint_label `json:"label"`
+	}
+	if err := json.Unmarshal(x, &raw); err != nil {
+		return err
+	}
+	*h = InlayHint(raw.inlayHint)
+	switch v := raw.Label.Value.(type) {
+	case string:
+		h.Label = []InlayHintLabelPart{{Value: v}}
+	case []InlayHintLabelPart:
+		h.Label = v
+	default:
+		h.Label = nil
+	}
+	return nil
+}
+
+// LabelText joins the values of the hint's label parts
+func (h InlayHint) LabelText() string {
+	var text strings.Builder
+	for _, part := range h.Label {
+		text.WriteString(part.Value)
+	}
+	return text.String()
+}
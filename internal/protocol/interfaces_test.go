@@ -0,0 +1,211 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestImplementationLocations(t *testing.T) {
+	rng := func(line uint32) Range {
+		return Range{Start: Position{Line: line, Character: 5}, End: Position{Line: line, Character: 10}}
+	}
+
+	tests := []struct {
+		name     string
+		result   Or_Result_textDocument_implementation
+		expected []Location
+	}{
+		{
+			name:     "null",
+			result:   Or_Result_textDocument_implementation{},
+			expected: nil,
+		},
+		{
+			name: "single location",
+			result: Or_Result_textDocument_implementation{Value: Definition{
+				Value: Location{URI: "file:///a.go", Range: rng(1)},
+			}},
+			expected: []Location{{URI: "file:///a.go", Range: rng(1)}},
+		},
+		{
+			name: "location list",
+			result: Or_Result_textDocument_implementation{Value: Definition{
+				Value: []Location{{URI: "file:///a.go", Range: rng(1)}, {URI: "file:///b.go", Range: rng(2)}},
+			}},
+			expected: []Location{{URI: "file:///a.go", Range: rng(1)}, {URI: "file:///b.go", Range: rng(2)}},
+		},
+		{
+			name: "links use the target selection range",
+			result: Or_Result_textDocument_implementation{Value: []LocationLink{
+				{TargetURI: "file:///a.go", TargetRange: rng(0), TargetSelectionRange: rng(3)},
+			}},
+			expected: []Location{{URI: "file:///a.go", Range: rng(3)}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			locations, err := tc.result.Locations()
+			if err != nil {
+				t.Fatalf("Locations failed: %v", err)
+			}
+			if !reflect.DeepEqual(locations, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, locations)
+			}
+		})
+	}
+}
+
+func TestImplementationLocations_FromJSON(t *testing.T) {
+	var result Or_Result_textDocument_implementation
+	data := `[{"uri": "file:///a.go", "range": {"start": {"line": 4, "character": 1}, "end": {"line": 4, "character": 8}}}]`
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+
+	locations, err := result.Locations()
+	if err != nil {
+		t.Fatalf("Locations failed: %v", err)
+	}
+	if len(locations) != 1 || locations[0].URI != "file:///a.go" || locations[0].Range.Start.Line != 4 {
+		t.Errorf("unexpected locations: %v", locations)
+	}
+}
+
+func TestDeclarationLocations(t *testing.T) {
+	loc := Location{URI: "file:///include/helper.hpp", Range: Range{End: Position{Character: 20}}}
+	result := Or_Result_textDocument_declaration{Value: Declaration{Value: []Location{loc}}}
+
+	locations, err := result.Locations()
+	if err != nil {
+		t.Fatalf("Locations failed: %v", err)
+	}
+	if !reflect.DeepEqual(locations, []Location{loc}) {
+		t.Errorf("expected %v, got %v", []Location{loc}, locations)
+	}
+}
+
+func TestParameterInformationLabel_Offsets(t *testing.T) {
+	var param ParameterInformation
+	if err := json.Unmarshal([]byte(`{"label": [5, 12]}`), &param); err != nil {
+		t.Fatalf("failed to decode parameter: %v", err)
+	}
+
+	offsets, ok := param.Label.Value.(Tuple_ParameterInformation_label_Item1)
+	if !ok {
+		t.Fatalf("expected offset label, got %T", param.Label.Value)
+	}
+	if offsets.Fld0 != 5 || offsets.Fld1 != 12 {
+		t.Errorf("expected [5, 12], got [%d, %d]", offsets.Fld0, offsets.Fld1)
+	}
+
+	data, err := json.Marshal(param.Label)
+	if err != nil {
+		t.Fatalf("failed to encode label: %v", err)
+	}
+	if string(data) != "[5,12]" {
+		t.Errorf("expected [5,12], got %s", data)
+	}
+}
+
+func TestCompletionList_AppliesItemDefaults(t *testing.T) {
+	data := `{
+		"isIncomplete": true,
+		"itemDefaults": {
+			"editRange": {"start": {"line": 3, "character": 5}, "end": {"line": 3, "character": 8}},
+			"insertTextFormat": 2
+		},
+		"items": [
+			{"label": "Println", "textEditText": "Println($0)"},
+			{"label": "Printf", "insertTextFormat": 1, "textEdit": {"range": {"start": {"line": 3, "character": 1}, "end": {"line": 3, "character": 8}}, "newText": "fmt.Printf"}}
+		]
+	}`
+
+	var result Or_Result_textDocument_completion
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+
+	list, err := result.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !list.IsIncomplete || len(list.Items) != 2 {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	edit, ok := list.Items[0].TextEdit.Value.(TextEdit)
+	if !ok {
+		t.Fatalf("expected default edit range to produce a TextEdit, got %T", list.Items[0].TextEdit.Value)
+	}
+	if edit.NewText != "Println($0)" || edit.Range.Start.Character != 5 || edit.Range.End.Character != 8 {
+		t.Errorf("unexpected default edit: %+v", edit)
+	}
+	if list.Items[0].InsertTextFormat == nil || *list.Items[0].InsertTextFormat != SnippetTextFormat {
+		t.Errorf("expected default insert text format to be applied")
+	}
+
+	own, ok := list.Items[1].TextEdit.Value.(TextEdit)
+	if !ok || own.NewText != "fmt.Printf" {
+		t.Errorf("expected item's own text edit to be kept, got %+v", list.Items[1].TextEdit)
+	}
+	if *list.Items[1].InsertTextFormat != PlainTextTextFormat {
+		t.Errorf("expected item's own insert text format to be kept")
+	}
+}
+
+func TestCompletionList_FromItems(t *testing.T) {
+	var result Or_Result_textDocument_completion
+	if err := json.Unmarshal([]byte(`[{"label": "a"}, {"label": "b"}]`), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+
+	list, err := result.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if list.IsIncomplete || len(list.Items) != 2 {
+		t.Errorf("unexpected list: %+v", list)
+	}
+}
+
+func TestInlayHint_Label(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+		parts    int
+	}{
+		{
+			name:     "string label",
+			json:     `{"position":{"line":3,"character":5},"label":": i32","kind":1,"paddingLeft":true}`,
+			expected: ": i32",
+			parts:    1,
+		},
+		{
+			name:     "label parts",
+			json:     `{"position":{"line":3,"character":5},"label":[{"value":": "},{"value":"Vec<T>","location":{"uri":"file:///vec.rs","range":{"start":{"line":1,"character":0},"end":{"line":1,"character":3}}}}],"kind":1}`,
+			expected: ": Vec<T>",
+			parts:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hint InlayHint
+			if err := json.Unmarshal([]byte(tt.json), &hint); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if hint.LabelText() != tt.expected {
+				t.Errorf("Expected label %q, got %q", tt.expected, hint.LabelText())
+			}
+			if len(hint.Label) != tt.parts {
+				t.Errorf("Expected %d label parts, got %d", tt.parts, len(hint.Label))
+			}
+			if hint.Position != (Position{Line: 3, Character: 5}) || hint.Kind != Type {
+				t.Errorf("Expected the other fields to be decoded, got %+v", hint)
+			}
+		})
+	}
+}
@@ -10,20 +10,20 @@ import (
 	"github.com/vector67/mcp-language-server/internal/protocol"
 )
 
-func GetCallers(ctx context.Context, client *lsp.Client, symbolName string, maxDepth int) (string, error) {
-	return getCallHierarchy(ctx, client, symbolName, maxDepth, recurseIncomingCalls)
+func GetCallers(ctx context.Context, clients []*lsp.Client, symbolName string, maxDepth int) (string, error) {
+	return getCallHierarchy(ctx, clients, symbolName, maxDepth, recurseIncomingCalls)
 }
 
-func GetCallees(ctx context.Context, client *lsp.Client, symbolName string, maxDepth int) (string, error) {
-	return getCallHierarchy(ctx, client, symbolName, maxDepth, recurseOutgoingCalls)
+func GetCallees(ctx context.Context, clients []*lsp.Client, symbolName string, maxDepth int) (string, error) {
+	return getCallHierarchy(ctx, clients, symbolName, maxDepth, recurseOutgoingCalls)
 }
 
 func getCallHierarchy(
-	ctx context.Context, client *lsp.Client, symbolName string, maxDepth int,
+	ctx context.Context, clients []*lsp.Client, symbolName string, maxDepth int,
 	recurse func(ctx context.Context, client *lsp.Client, item protocol.CallHierarchyItem, result *strings.Builder, depth int, maxDepth int),
 ) (string, error) {
 	// First get the symbol location like ReadDefinition does
-	symbolName, results, err := QuerySymbol(ctx, client, symbolName)
+	symbolName, results, err := QuerySymbol(ctx, clients, symbolName)
 	if err != nil {
 		return "", err
 	}
@@ -31,7 +31,8 @@ func getCallHierarchy(
 	// After this point we just return errors instead of erroring out
 	var result strings.Builder
 
-	for _, symbol := range results {
+	for _, found := range results {
+		client, symbol := found.Client, found.Symbol
 		var separator string
 		if strings.Contains(symbolName, ".") {
 			separator = "."
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+	"github.com/vector67/mcp-language-server/internal/utilities"
+)
+
+// fetchCodeActions requests the code actions available for a range of a file.
+// Line and column numbers are 1-indexed. The order returned by the server is
+// preserved so that indexes remain stable between listing and applying.
+func fetchCodeActions(ctx context.Context, client *lsp.Client, filePath string, startLine, startColumn, endLine, endColumn int) ([]protocol.Or_Result_textDocument_codeAction_Item0_Elem, protocol.Range, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, protocol.Range{}, fmt.Errorf("could not open file: %v", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	start, err := positionAt(filePath, startLine, startColumn, client.PositionEncoding())
+	if err != nil {
+		return nil, protocol.Range{}, err
+	}
+	end, err := positionAt(filePath, endLine, endColumn, client.PositionEncoding())
+	if err != nil {
+		return nil, protocol.Range{}, err
+	}
+	rng := protocol.Range{Start: start, End: end}
+
+	// Pass along the diagnostics overlapping the range so the server can
+	// offer quick fixes for them
+	var diagnostics []protocol.Diagnostic
+	for _, diag := range client.GetFileDiagnostics(uri) {
+		if utilities.RangesOverlap(diag.Range, rng) {
+			diagnostics = append(diagnostics, diag)
+		}
+	}
+	if diagnostics == nil {
+		diagnostics = []protocol.Diagnostic{}
+	}
+
+	actions, err := client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: uri,
+		},
+		Range: rng,
+		Context: protocol.CodeActionContext{
+			Diagnostics: diagnostics,
+		},
+	})
+	if err != nil {
+		return nil, protocol.Range{}, fmt.Errorf("failed to get code actions: %w", err)
+	}
+
+	return actions, rng, nil
+}
+
+// GetCodeActions lists the refactorings and quick fixes available for a range of a file
+func GetCodeActions(ctx context.Context, client *lsp.Client, filePath string, startLine, startColumn, endLine, endColumn int) (string, error) {
+	actions, rng, err := fetchCodeActions(ctx, client, filePath, startLine, startColumn, endLine, endColumn)
+	if err != nil {
+		return "", err
+	}
+
+	if len(actions) == 0 {
+		return fmt.Sprintf("No code actions available for %s at L%d:C%d - L%d:C%d",
+			filePath, rng.Start.Line+1, rng.Start.Character+1, rng.End.Line+1, rng.End.Character+1), nil
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Code actions for %s at L%d:C%d - L%d:C%d:\n\n",
+		filePath, rng.Start.Line+1, rng.Start.Character+1, rng.End.Line+1, rng.End.Character+1))
+
+	for i, item := range actions {
+		switch v := item.Value.(type) {
+		case protocol.CodeAction:
+			output.WriteString(fmt.Sprintf("[%d] %s\n", i+1, v.Title))
+			if v.Kind != "" {
+				output.WriteString(fmt.Sprintf("    Kind: %s\n", v.Kind))
+			}
+			if v.IsPreferred {
+				output.WriteString("    Preferred: true\n")
+			}
+			for _, diag := range v.Diagnostics {
+				output.WriteString(fmt.Sprintf("    Fixes: L%d:C%d: %s\n",
+					diag.Range.Start.Line+1, diag.Range.Start.Character+1, diag.Message))
+			}
+			if v.Disabled != nil {
+				output.WriteString(fmt.Sprintf("    Disabled: %s\n", v.Disabled.Reason))
+			}
+			if v.Command != nil {
+				output.WriteString(fmt.Sprintf("    Command: %s\n", v.Command.Command))
+			}
+		case protocol.Command:
+			output.WriteString(fmt.Sprintf("[%d] %s\n", i+1, v.Title))
+			output.WriteString(fmt.Sprintf("    Command: %s\n", v.Command))
+		default:
+			output.WriteString(fmt.Sprintf("[%d] Unknown code action type: %T\n", i+1, v))
+		}
+	}
+
+	output.WriteString(fmt.Sprintf("\nFound %d code actions.\n", len(actions)))
+
+	return output.String(), nil
+}
+
+// ApplyCodeAction applies the code action at the given index (1-indexed, from
+// GetCodeActions output) for a range of a file. Edits are written to disk and
+// commands are executed on the server.
+func ApplyCodeAction(ctx context.Context, client *lsp.Client, filePath string, startLine, startColumn, endLine, endColumn int, index int) (string, error) {
+	actions, _, err := fetchCodeActions(ctx, client, filePath, startLine, startColumn, endLine, endColumn)
+	if err != nil {
+		return "", err
+	}
+
+	if len(actions) == 0 {
+		return "", fmt.Errorf("no code actions available for this range")
+	}
+
+	if index < 1 || index > len(actions) {
+		return "", fmt.Errorf("invalid code action index: %d. Available range: 1-%d", index, len(actions))
+	}
+
+	var title string
+	var edit *protocol.WorkspaceEdit
+	var command *protocol.Command
+
+	switch v := actions[index-1].Value.(type) {
+	case protocol.CodeAction:
+		if v.Disabled != nil {
+			return "", fmt.Errorf("code action %q is disabled: %s", v.Title, v.Disabled.Reason)
+		}
+
+		// Resolve the code action if the server deferred computing its edit.
+		// Servers with a resolve provider may omit data, so check both.
+		if v.Edit == nil && (v.Data != nil || client.SupportsCodeActionResolve()) {
+			resolved, err := client.ResolveCodeAction(ctx, v)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve code action: %v", err)
+			}
+			v = resolved
+		}
+
+		title = v.Title
+		edit = v.Edit
+		command = v.Command
+	case protocol.Command:
+		title = v.Title
+		command = &v
+	default:
+		return "", fmt.Errorf("unknown code action type: %T", v)
+	}
+
+	if edit == nil && command == nil {
+		return "", fmt.Errorf("code action %q has no edit or command", title)
+	}
+
+	var changedFiles []string
+
+	// Per the spec, the edit is applied before the command is executed
+	if edit != nil {
+		if err := utilities.ApplyWorkspaceEdit(*edit, client.PositionEncoding()); err != nil {
+			return "", fmt.Errorf("failed to apply code action edit: %v", err)
+		}
+
+		changedFiles = utilities.AffectedFiles(*edit)
+		for _, path := range changedFiles {
+			if err := client.NotifyChange(ctx, path); err != nil {
+				toolsLogger.Warn("Failed to notify language server of change to %s: %v", path, err)
+			}
+		}
+	}
+
+	if command != nil {
+		// Edits produced by the command arrive through workspace/applyEdit
+		_, err := client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
+			Command:   command.Command,
+			Arguments: command.Arguments,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to execute code action command: %v", err)
+		}
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Successfully applied code action: %s\n", title))
+	if len(changedFiles) > 0 {
+		sort.Strings(changedFiles)
+		output.WriteString(fmt.Sprintf("Updated %d files:\n", len(changedFiles)))
+		for _, path := range changedFiles {
+			output.WriteString(path + "\n")
+		}
+	}
+	if command != nil {
+		output.WriteString(fmt.Sprintf("Executed command: %s\n", command.Command))
+	}
+
+	return output.String(), nil
+}
@@ -0,0 +1,378 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+	"github.com/vector67/mcp-language-server/internal/utilities"
+)
+
+// DefaultCompletionLimit is the number of completions shown when no limit is given
+const DefaultCompletionLimit = 50
+
+// completionResolveLimit caps how many listed items are resolved for missing
+// details and documentation, since each one is a round trip to the server
+const completionResolveLimit = 10
+
+// GetCompletions lists the completions available at the given position (1-indexed
+// line and column). If prefix is not empty it is inserted at the position for the
+// duration of the request, as if it had been typed, and the file is left unchanged.
+func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, line, column int, prefix string, limit int) (string, error) {
+	// Keep the file open until the prefix has been removed again
+	defer client.PinFile(filePath)()
+
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	if limit <= 0 {
+		limit = DefaultCompletionLimit
+	}
+
+	cursor, err := completionCursor(filePath, line, column, prefix, client.PositionEncoding())
+	if err != nil {
+		return "", err
+	}
+
+	if prefix != "" {
+		content, err := insertCompletionPrefix(filePath, line, column, prefix)
+		if err != nil {
+			return "", err
+		}
+		if err := client.NotifyContent(ctx, filePath, content); err != nil {
+			return "", fmt.Errorf("failed to insert prefix: %v", err)
+		}
+		defer func() {
+			// Restore the server's view of the file to what is on disk
+			if err := client.NotifyChange(ctx, filePath); err != nil {
+				toolsLogger.Warn("Failed to restore %s after completion: %v", filePath, err)
+			}
+		}()
+	}
+
+	list, err := fetchCompletions(ctx, client, filePath, cursor)
+	if err != nil {
+		return "", err
+	}
+
+	if len(list.Items) == 0 {
+		return fmt.Sprintf("No completions available at %s L%d:C%d", filePath, line, column), nil
+	}
+
+	shown := list.Items[:min(limit, len(list.Items))]
+	if client.SupportsCompletionResolve() {
+		for i := range shown[:min(completionResolveLimit, len(shown))] {
+			if shown[i].Documentation != nil && shown[i].Detail != "" {
+				continue
+			}
+			resolved, err := client.ResolveCompletionItem(ctx, shown[i])
+			if err != nil {
+				toolsLogger.Warn("Failed to resolve completion %s: %v", shown[i].Label, err)
+				continue
+			}
+			shown[i] = resolved
+		}
+	}
+
+	var result strings.Builder
+	if len(shown) < len(list.Items) {
+		result.WriteString(fmt.Sprintf("Found %d completions (showing first %d):\n", len(list.Items), len(shown)))
+	} else {
+		result.WriteString(fmt.Sprintf("Found %d completions:\n", len(list.Items)))
+	}
+	if list.IsIncomplete {
+		result.WriteString("The list is incomplete; type a longer prefix to narrow it down.\n")
+	}
+	result.WriteString("\n")
+
+	for i, item := range shown {
+		result.WriteString(formatCompletionItem(i+1, item))
+	}
+
+	return result.String(), nil
+}
+
+// ApplyCompletion inserts the completion at the given index (1-indexed, from
+// GetCompletions output) at the given position. The prefix, if any, is written to
+// the file first. The item's additional edits, such as imports, are applied too.
+func ApplyCompletion(ctx context.Context, client *lsp.Client, filePath string, line, column int, prefix string, index int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	cursor, err := completionCursor(filePath, line, column, prefix, client.PositionEncoding())
+	if err != nil {
+		return "", err
+	}
+
+	original, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	// Put the file back the way it was if the completion cannot be applied
+	applied := false
+	defer func() {
+		if applied || prefix == "" {
+			return
+		}
+		if err := os.WriteFile(filePath, original, 0644); err != nil {
+			toolsLogger.Error("Failed to restore %s: %v", filePath, err)
+			return
+		}
+		if err := client.NotifyChange(ctx, filePath); err != nil {
+			toolsLogger.Warn("Failed to notify language server of change to %s: %v", filePath, err)
+		}
+	}()
+
+	if prefix != "" {
+		content, err := insertCompletionPrefix(filePath, line, column, prefix)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write prefix: %v", err)
+		}
+		if err := client.NotifyChange(ctx, filePath); err != nil {
+			return "", fmt.Errorf("failed to notify language server of prefix: %v", err)
+		}
+	}
+
+	list, err := fetchCompletions(ctx, client, filePath, cursor)
+	if err != nil {
+		return "", err
+	}
+
+	if len(list.Items) == 0 {
+		return "", fmt.Errorf("no completions available at this position")
+	}
+
+	if index < 1 || index > len(list.Items) {
+		return "", fmt.Errorf("invalid completion index: %d. Available range: 1-%d", index, len(list.Items))
+	}
+
+	item := list.Items[index-1]
+
+	// Additional edits such as imports are often only computed on resolve
+	if client.SupportsCompletionResolve() {
+		resolved, err := client.ResolveCompletionItem(ctx, item)
+		if err != nil {
+			toolsLogger.Warn("Failed to resolve completion %s: %v", item.Label, err)
+		} else {
+			item = resolved
+		}
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := strings.Split(string(content), "\n")
+
+	edits := append([]protocol.TextEdit{completionEdit(item, lines, cursor, client.PositionEncoding())}, item.AdditionalTextEdits...)
+	if err := utilities.ApplyTextEdits(protocol.DocumentUri("file://"+filePath), edits, client.PositionEncoding()); err != nil {
+		return "", fmt.Errorf("failed to apply completion: %v", err)
+	}
+	applied = true
+
+	// Notify the language server that the file contents changed on disk
+	if err := client.NotifyChange(ctx, filePath); err != nil {
+		toolsLogger.Warn("Failed to notify language server of change to %s: %v", filePath, err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Successfully applied completion: %s\n", item.Label))
+	if len(item.AdditionalTextEdits) > 0 {
+		result.WriteString(fmt.Sprintf("Applied %d additional edits (e.g. imports)\n", len(item.AdditionalTextEdits)))
+	}
+	if item.Command != nil {
+		result.WriteString(fmt.Sprintf("The completion also requests command %s, which was not executed\n", item.Command.Command))
+	}
+
+	return result.String(), nil
+}
+
+// fetchCompletions requests completions at a position and orders them the way
+// the server asked, by sort text and then label
+func fetchCompletions(ctx context.Context, client *lsp.Client, filePath string, position protocol.Position) (protocol.CompletionList, error) {
+	result, err := client.Completion(ctx, protocol.CompletionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: position,
+		},
+		Context: protocol.CompletionContext{
+			TriggerKind: protocol.Invoked,
+		},
+	})
+	if err != nil {
+		return protocol.CompletionList{}, fmt.Errorf("failed to get completions: %v", err)
+	}
+
+	list, err := result.List()
+	if err != nil {
+		return protocol.CompletionList{}, err
+	}
+
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return completionSortKey(list.Items[i]) < completionSortKey(list.Items[j])
+	})
+
+	return list, nil
+}
+
+func completionSortKey(item protocol.CompletionItem) string {
+	if item.SortText != "" {
+		return item.SortText
+	}
+	return item.Label
+}
+
+// completionCursor returns the position, in the given encoding, just after the
+// prefix once it is inserted
+func completionCursor(filePath string, line, column int, prefix string, encoding protocol.PositionEncodingKind) (protocol.Position, error) {
+	if strings.ContainsAny(prefix, "\r\n") {
+		return protocol.Position{}, fmt.Errorf("prefix must not contain line breaks")
+	}
+	if line < 1 || column < 1 {
+		return protocol.Position{}, fmt.Errorf("line and column must be at least 1")
+	}
+
+	position, err := positionAt(filePath, line, column, encoding)
+	if err != nil {
+		return protocol.Position{}, err
+	}
+	position.Character += utilities.TextLength(prefix, encoding)
+	return position, nil
+}
+
+// insertCompletionPrefix returns the contents of the file with prefix inserted at
+// the given position (1-indexed line and column, counted in characters)
+func insertCompletionPrefix(filePath string, line, column int, prefix string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if line > len(lines) {
+		return "", fmt.Errorf("line %d is beyond the end of the file", line)
+	}
+	text := lines[line-1]
+	if column-1 > utf8.RuneCountInString(strings.TrimSuffix(text, "\r")) {
+		return "", fmt.Errorf("column %d is beyond the end of line %d", column, line)
+	}
+
+	// UTF-32 characters are runes, so this finds the byte offset of the column
+	offset := utilities.CharacterToByteOffset(text, uint32(column-1), protocol.UTF32)
+	lines[line-1] = text[:offset] + prefix + text[offset:]
+	return strings.Join(lines, "\n"), nil
+}
+
+// completionEdit returns the edit that inserts a completion item. Items without
+// a text edit replace the identifier characters before the cursor.
+func completionEdit(item protocol.CompletionItem, lines []string, cursor protocol.Position, encoding protocol.PositionEncodingKind) protocol.TextEdit {
+	var edit protocol.TextEdit
+	switch v := textEditValue(item).(type) {
+	case protocol.TextEdit:
+		edit = v
+	case protocol.InsertReplaceEdit:
+		edit = protocol.TextEdit{Range: v.Insert, NewText: v.NewText}
+	default:
+		newText := item.InsertText
+		if newText == "" {
+			newText = item.Label
+		}
+
+		start := cursor
+		if int(cursor.Line) < len(lines) {
+			text := lines[cursor.Line]
+			begin := utilities.CharacterToByteOffset(text, cursor.Character, encoding)
+			for begin > 0 {
+				r, size := utf8.DecodeLastRuneInString(text[:begin])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				begin -= size
+			}
+			start.Character = utilities.ByteOffsetToCharacter(text, begin, encoding)
+		}
+		edit = protocol.TextEdit{Range: protocol.Range{Start: start, End: cursor}, NewText: newText}
+	}
+
+	if item.InsertTextFormat != nil && *item.InsertTextFormat == protocol.SnippetTextFormat {
+		edit.NewText = snippetToPlainText(edit.NewText)
+	}
+	return edit
+}
+
+func textEditValue(item protocol.CompletionItem) any {
+	if item.TextEdit == nil {
+		return nil
+	}
+	return item.TextEdit.Value
+}
+
+var (
+	snippetPlaceholder = regexp.MustCompile(`\$\{\d+:([^{}]*)\}`)
+	snippetChoice      = regexp.MustCompile(`\$\{\d+\|([^,|]*)[^}]*\|\}`)
+	snippetTabStop     = regexp.MustCompile(`\$\{\d+\}|\$\d+`)
+)
+
+// snippetToPlainText expands a snippet to its default text: placeholders become
+// their default values, choices their first option, and tab stops are removed
+func snippetToPlainText(snippet string) string {
+	// Hide escaped characters so they are not mistaken for snippet syntax
+	escapes := strings.NewReplacer(`\\`, "\x00", `\$`, "\x01", `\}`, "\x02")
+	text := escapes.Replace(snippet)
+	for {
+		expanded := snippetPlaceholder.ReplaceAllString(text, "$1")
+		expanded = snippetChoice.ReplaceAllString(expanded, "$1")
+		if expanded == text {
+			break
+		}
+		text = expanded
+	}
+	text = snippetTabStop.ReplaceAllString(text, "")
+	return strings.NewReplacer("\x00", `\`, "\x01", "$", "\x02", "}").Replace(text)
+}
+
+// formatCompletionItem renders one completion with its kind, detail and documentation
+func formatCompletionItem(index int, item protocol.CompletionItem) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[%d] %s", index, item.Label))
+	if item.LabelDetails != nil && item.LabelDetails.Detail != "" {
+		result.WriteString(item.LabelDetails.Detail)
+	}
+	if kind, ok := protocol.TableCompletionKindMap[item.Kind]; ok {
+		result.WriteString(fmt.Sprintf(" (%s)", kind))
+	}
+	if item.Deprecated || slices.Contains(item.Tags, protocol.ComplDeprecated) {
+		result.WriteString(" [deprecated]")
+	}
+	result.WriteString("\n")
+
+	if item.Detail != "" {
+		result.WriteString(fmt.Sprintf("    %s\n", strings.Join(strings.Fields(item.Detail), " ")))
+	} else if item.LabelDetails != nil && item.LabelDetails.Description != "" {
+		result.WriteString(fmt.Sprintf("    %s\n", item.LabelDetails.Description))
+	}
+	if item.Documentation != nil {
+		if doc := markupToPlainText(item.Documentation.Value); doc != "" {
+			result.WriteString(indentLines(doc, "    "))
+		}
+	}
+	return result.String()
+}
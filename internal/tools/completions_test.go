@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func TestSnippetToPlainText(t *testing.T) {
+	tests := []struct {
+		snippet  string
+		expected string
+	}{
+		{snippet: "Println(${1:a ...any})$0", expected: "Println(a ...any)"},
+		{snippet: "for ${1:i} := 0; $1 < ${2:n}; $1++ {\n\t$0\n}", expected: "for i := 0;  < n; ++ {\n\t\n}"},
+		{snippet: "${1:outer ${2:inner}}", expected: "outer inner"},
+		{snippet: "log(${1|info,warn,error|})", expected: "log(info)"},
+		{snippet: "cost: \\$${1:5}", expected: "cost: $5"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.snippet, func(t *testing.T) {
+			assert.Equal(t, tc.expected, snippetToPlainText(tc.snippet))
+		})
+	}
+}
+
+func TestCompletionEdit(t *testing.T) {
+	snippet := protocol.SnippetTextFormat
+	cursor := protocol.Position{Line: 0, Character: 10}
+	lines := []string{"\tfmt.Prin"}
+
+	t.Run("text edit", func(t *testing.T) {
+		rng := protocol.Range{Start: protocol.Position{Line: 0, Character: 5}, End: cursor}
+		item := protocol.CompletionItem{
+			Label:            "Println",
+			InsertTextFormat: &snippet,
+			TextEdit:         &protocol.Or_CompletionItem_textEdit{Value: protocol.TextEdit{Range: rng, NewText: "Println(${1:})"}},
+		}
+		assert.Equal(t, protocol.TextEdit{Range: rng, NewText: "Println()"}, completionEdit(item, lines, cursor, protocol.UTF16))
+	})
+
+	t.Run("insert replace edit uses insert range", func(t *testing.T) {
+		insert := protocol.Range{Start: protocol.Position{Line: 0, Character: 5}, End: cursor}
+		replace := protocol.Range{Start: insert.Start, End: protocol.Position{Line: 0, Character: 12}}
+		item := protocol.CompletionItem{
+			Label:    "Println",
+			TextEdit: &protocol.Or_CompletionItem_textEdit{Value: protocol.InsertReplaceEdit{NewText: "Println", Insert: insert, Replace: replace}},
+		}
+		assert.Equal(t, protocol.TextEdit{Range: insert, NewText: "Println"}, completionEdit(item, lines, cursor, protocol.UTF16))
+	})
+
+	t.Run("no text edit replaces the word before the cursor", func(t *testing.T) {
+		item := protocol.CompletionItem{Label: "Println", InsertText: "Println"}
+		expected := protocol.TextEdit{
+			Range:   protocol.Range{Start: protocol.Position{Line: 0, Character: 5}, End: cursor},
+			NewText: "Println",
+		}
+		assert.Equal(t, expected, completionEdit(item, lines, cursor, protocol.UTF16))
+	})
+
+	t.Run("word before the cursor is measured in the server's encoding", func(t *testing.T) {
+		lines := []string{"	🙂 := naï"}
+		item := protocol.CompletionItem{Label: "naïve"}
+		tests := map[protocol.PositionEncodingKind]protocol.Range{
+			protocol.UTF8:  {Start: protocol.Position{Line: 0, Character: 9}, End: protocol.Position{Line: 0, Character: 13}},
+			protocol.UTF16: {Start: protocol.Position{Line: 0, Character: 7}, End: protocol.Position{Line: 0, Character: 10}},
+		}
+		for encoding, rng := range tests {
+			assert.Equal(t, protocol.TextEdit{Range: rng, NewText: "naïve"}, completionEdit(item, lines, rng.End, encoding), encoding)
+		}
+	})
+}
+
+func TestInsertCompletionPrefix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	writeTestFile(t, path, "package main\n\nfunc main() {\n\tfmt.\n}\n")
+
+	content, err := insertCompletionPrefix(path, 4, 6, "Pri")
+	if err != nil {
+		t.Fatalf("insertCompletionPrefix failed: %v", err)
+	}
+	assert.Equal(t, "package main\n\nfunc main() {\n\tfmt.Pri\n}\n", content)
+
+	cursor, err := completionCursor(path, 4, 6, "Pri", protocol.UTF16)
+	if err != nil {
+		t.Fatalf("completionCursor failed: %v", err)
+	}
+	assert.Equal(t, protocol.Position{Line: 3, Character: 8}, cursor)
+
+	_, err = insertCompletionPrefix(path, 4, 20, "Pri")
+	assert.Error(t, err)
+
+	_, err = completionCursor(path, 4, 6, "a\nb", protocol.UTF16)
+	assert.Error(t, err)
+}
+
+func TestFormatCompletionItem(t *testing.T) {
+	item := protocol.CompletionItem{
+		Label:         "Println",
+		Kind:          protocol.FunctionCompletion,
+		Detail:        "func(a ...any) (n int, err error)",
+		Documentation: &protocol.Or_CompletionItem_documentation{Value: protocol.MarkupContent{Kind: protocol.Markdown, Value: "Println formats using the default formats."}},
+		Tags:          []protocol.CompletionItemTag{protocol.ComplDeprecated},
+	}
+
+	expected := "[3] Println (Function) [deprecated]\n" +
+		"    func(a ...any) (n int, err error)\n" +
+		"    Println formats using the default formats.\n"
+	assert.Equal(t, expected, formatCompletionItem(3, item))
+}
@@ -17,10 +17,9 @@ func GetContentInfo(ctx context.Context, client *lsp.Client, filePath string, li
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
 
-	// Convert 1-indexed line/column to 0-indexed for LSP protocol
-	position := protocol.Position{
-		Line:      uint32(line - 1),
-		Character: uint32(column - 1),
+	position, err := positionAt(filePath, line, column, client.PositionEncoding())
+	if err != nil {
+		return "", err
 	}
 
 	location := protocol.Location{
@@ -36,16 +35,17 @@ func GetContentInfo(ctx context.Context, client *lsp.Client, filePath string, li
 		return "", err
 	}
 
+	definitionPath := strings.TrimPrefix(string(loc.URI), "file://")
 	locationInfo := fmt.Sprintf(
 		"Symbol: %s\n"+
 			"File: %s\n"+
 			"Range: L%d:C%d - L%d:C%d\n\n",
 		symbol.GetName(),
-		strings.TrimPrefix(string(loc.URI), "file://"),
+		definitionPath,
 		loc.Range.Start.Line+1,
-		loc.Range.Start.Character+1,
+		columnAt(definitionPath, loc.Range.Start, client.PositionEncoding()),
 		loc.Range.End.Line+1,
-		loc.Range.End.Character+1,
+		columnAt(definitionPath, loc.Range.End, client.PositionEncoding()),
 	)
 
 	definition = addLineNumbers(definition, int(loc.Range.Start.Line)+1)
@@ -3,70 +3,28 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/protocol"
 )
 
-func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
-	symbolName, results, err := QuerySymbol(ctx, client, symbolName)
+func ReadDefinition(ctx context.Context, clients []*lsp.Client, symbolName string) (string, error) {
+	symbolName, results, err := QuerySymbol(ctx, clients, symbolName)
 	if err != nil {
 		return "", err
 	}
 
 	var definitions []string
-	for _, symbol := range results {
-		kind := ""
-		container := ""
-
+	for _, result := range results {
+		client, symbol := result.Client, result.Symbol
 		// Skip symbols that we are not looking for. workspace/symbol may return
-		// a large number of fuzzy matches. This handles BaseSymbolInformation
-		doesSymbolMatch := func(vKind protocol.SymbolKind, vContainerName string) bool {
-			thisName := symbol.GetName()
-
-			kind = fmt.Sprintf("Kind: %s\n", protocol.TableKindMap[vKind])
-			if vContainerName != "" {
-				container = fmt.Sprintf("Container Name: %s\n", vContainerName)
-			}
-
-			if thisName == symbolName {
-				return true
-			}
-
-			// Handle different matching strategies based on the search term
-			if strings.Contains(symbolName, ".") {
-				// For qualified names like "Type.Method", don't do fuzzy match
-
-			} else if vKind == protocol.Method {
-				// For methods, only match if the method name matches exactly Type.symbolName or Type::symbolName or symbolName
-				if strings.HasSuffix(thisName, "::"+symbolName) || strings.HasSuffix(symbolName, "::"+thisName) {
-					return true
-				}
-
-				if strings.HasSuffix(thisName, "."+symbolName) || strings.HasSuffix(symbolName, "."+thisName) {
-					return true
-				}
-			}
-
-			return false
-		}
-
-		switch v := symbol.(type) {
-		case *protocol.SymbolInformation:
-			if !doesSymbolMatch(v.Kind, v.ContainerName) {
-				continue
-			}
-
-		case *protocol.WorkspaceSymbol:
-			if !doesSymbolMatch(v.Kind, v.ContainerName) {
-				continue
-			}
-		default:
-			if symbol.GetName() != symbolName {
-				continue
-			}
+		// a large number of fuzzy matches.
+		if !symbolMatches(symbol, symbolName) {
+			continue
 		}
+		kind, container := symbolKindAndContainer(symbol)
 
 		toolsLogger.Debug("Found symbol: %s", symbol.GetName())
 		loc := symbol.GetLocation()
@@ -77,30 +35,13 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 			continue
 		}
 
-		banner := "---\n\n"
 		definition, loc, _, err := GetFullDefinition(ctx, client, loc)
-		locationInfo := fmt.Sprintf(
-			"Symbol: %s\n"+
-				"File: %s\n"+
-				kind+
-				container+
-				"Range: L%d:C%d - L%d:C%d\n\n",
-			symbol.GetName(),
-			strings.TrimPrefix(string(loc.URI), "file://"),
-			loc.Range.Start.Line+1,
-			loc.Range.Start.Character+1,
-			loc.Range.End.Line+1,
-			loc.Range.End.Character+1,
-		)
-
 		if err != nil {
 			toolsLogger.Error("Error getting definition: %v", err)
 			continue
 		}
 
-		definition = addLineNumbers(definition, int(loc.Range.Start.Line)+1)
-
-		definitions = append(definitions, banner+locationInfo+definition+"\n")
+		definitions = append(definitions, formatDefinition(symbol.GetName(), kind, container, definition, loc))
 	}
 
 	if len(definitions) == 0 {
@@ -109,3 +50,126 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 
 	return strings.Join(definitions, ""), nil
 }
+
+// symbolMatches reports whether a workspace/symbol result is the symbol being
+// looked up rather than one of the other fuzzy matches
+func symbolMatches(symbol protocol.WorkspaceSymbolResult, symbolName string) bool {
+	var vKind protocol.SymbolKind
+	switch v := symbol.(type) {
+	case *protocol.SymbolInformation:
+		vKind = v.Kind
+	case *protocol.WorkspaceSymbol:
+		vKind = v.Kind
+	default:
+		return symbol.GetName() == symbolName
+	}
+
+	thisName := symbol.GetName()
+	if thisName == symbolName {
+		return true
+	}
+
+	// Handle different matching strategies based on the search term
+	if strings.Contains(symbolName, ".") {
+		// For qualified names like "Type.Method", don't do fuzzy match
+
+	} else if vKind == protocol.Method {
+		// For methods, only match if the method name matches exactly Type.symbolName or Type::symbolName or symbolName
+		if strings.HasSuffix(thisName, "::"+symbolName) || strings.HasSuffix(symbolName, "::"+thisName) {
+			return true
+		}
+
+		if strings.HasSuffix(thisName, "."+symbolName) || strings.HasSuffix(symbolName, "."+thisName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// symbolKindAndContainer returns the display name of a symbol's kind and its container name
+func symbolKindAndContainer(symbol protocol.WorkspaceSymbolResult) (string, string) {
+	switch v := symbol.(type) {
+	case *protocol.SymbolInformation:
+		return protocol.TableKindMap[v.Kind], v.ContainerName
+	case *protocol.WorkspaceSymbol:
+		return protocol.TableKindMap[v.Kind], v.ContainerName
+	}
+	return "", ""
+}
+
+// formatDefinition renders the source of a definition below a banner describing
+// the symbol and where it was found
+func formatDefinition(name string, kind string, container string, definition string, loc protocol.Location) string {
+	var kindInfo, containerInfo string
+	if kind != "" {
+		kindInfo = fmt.Sprintf("Kind: %s\n", kind)
+	}
+	if container != "" {
+		containerInfo = fmt.Sprintf("Container Name: %s\n", container)
+	}
+
+	banner := "---\n\n"
+	locationInfo := fmt.Sprintf(
+		"Symbol: %s\n"+
+			"File: %s\n"+
+			kindInfo+
+			containerInfo+
+			"Range: L%d:C%d - L%d:C%d\n\n",
+		name,
+		strings.TrimPrefix(string(loc.URI), "file://"),
+		loc.Range.Start.Line+1,
+		loc.Range.Start.Character+1,
+		loc.Range.End.Line+1,
+		loc.Range.End.Character+1,
+	)
+
+	definition = addLineNumbers(definition, int(loc.Range.Start.Line)+1)
+
+	return banner + locationInfo + definition + "\n"
+}
+
+// formatLocationDefinitions renders the full source of the symbol at each
+// location in the same banner-and-body format as ReadDefinition, sorted by
+// file and line
+func formatLocationDefinitions(ctx context.Context, client *lsp.Client, locations []protocol.Location) string {
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].URI != locations[j].URI {
+			return locations[i].URI < locations[j].URI
+		}
+		return positionBefore(locations[i].Range.Start, locations[j].Range.Start)
+	})
+
+	seen := make(map[protocol.Location]bool)
+	var definitions []string
+	for _, loc := range locations {
+		if seen[loc] {
+			continue
+		}
+		seen[loc] = true
+
+		err := client.OpenFile(ctx, loc.URI.Path())
+		if err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+
+		definition, fullLoc, symbol, err := GetFullDefinition(ctx, client, loc)
+		if err != nil {
+			toolsLogger.Error("Error getting definition: %v", err)
+			continue
+		}
+
+		var kind string
+		switch v := symbol.(type) {
+		case *protocol.DocumentSymbol:
+			kind = protocol.TableKindMap[v.Kind]
+		case *protocol.SymbolInformation:
+			kind = protocol.TableKindMap[v.Kind]
+		}
+
+		definitions = append(definitions, formatDefinition(symbol.GetName(), kind, "", definition, fullLoc))
+	}
+
+	return strings.Join(definitions, "")
+}
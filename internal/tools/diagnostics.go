@@ -21,6 +21,9 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 		}
 	}
 
+	// Diagnostics are only published for open files
+	defer client.PinFile(filePath)()
+
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
 		return "", fmt.Errorf("could not open file: %v", err)
@@ -29,8 +32,21 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 	// Convert the file path to URI format
 	uri := protocol.DocumentUri("file://" + filePath)
 
-	// Wait for diagnostics to arrive via publishDiagnostics notification
-	diagnostics, _ := client.WaitForDiagnostics(ctx, uri, 5*time.Second)
+	// Ask servers that support it for the diagnostics, and otherwise wait for
+	// them to arrive via publishDiagnostics notification
+	var diagnostics []protocol.Diagnostic
+	pulled := false
+	if client.SupportsPullDiagnostics() {
+		diagnostics, err = client.PullDiagnostics(ctx, uri)
+		if err != nil {
+			toolsLogger.Warn("Failed to pull diagnostics for %s, waiting for published ones: %v", filePath, err)
+		} else {
+			pulled = true
+		}
+	}
+	if !pulled {
+		diagnostics, _ = client.WaitForDiagnostics(ctx, uri, 5*time.Second)
+	}
 
 	if len(diagnostics) == 0 {
 		return "No diagnostics found for " + filePath, nil
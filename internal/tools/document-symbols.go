@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// outlineNode is a symbol in a file outline along with its nested symbols
+type outlineNode struct {
+	Name     string
+	Detail   string
+	Kind     protocol.SymbolKind
+	Range    protocol.Range
+	Children []*outlineNode
+}
+
+// GetDocumentSymbols renders the symbols of a file as a hierarchical outline
+func GetDocumentSymbols(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	symResult, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get document symbols: %w", err)
+	}
+
+	var nodes []*outlineNode
+	switch v := symResult.Value.(type) {
+	case []protocol.DocumentSymbol:
+		nodes = outlineFromDocumentSymbols(v)
+	case []protocol.SymbolInformation:
+		nodes = outlineFromSymbolInformation(v)
+	case nil:
+	default:
+		return "", fmt.Errorf("unknown document symbol type: %T", v)
+	}
+
+	if len(nodes) == 0 {
+		return fmt.Sprintf("No symbols found in %s", filePath), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(filePath)
+	result.WriteString("\n")
+	writeOutline(&result, nodes, 0)
+
+	return result.String(), nil
+}
+
+// outlineFromDocumentSymbols converts hierarchical document symbols, keeping their nesting
+func outlineFromDocumentSymbols(symbols []protocol.DocumentSymbol) []*outlineNode {
+	nodes := make([]*outlineNode, 0, len(symbols))
+	for _, sym := range symbols {
+		nodes = append(nodes, &outlineNode{
+			Name:     sym.Name,
+			Detail:   sym.Detail,
+			Kind:     sym.Kind,
+			Range:    sym.Range,
+			Children: outlineFromDocumentSymbols(sym.Children),
+		})
+	}
+	sortOutline(nodes)
+	return nodes
+}
+
+// outlineFromSymbolInformation converts a flat symbol list into a tree by
+// nesting each symbol inside the smallest preceding symbol whose range contains it.
+// The container name is not shown since the nesting already conveys it.
+func outlineFromSymbolInformation(symbols []protocol.SymbolInformation) []*outlineNode {
+	nodes := make([]*outlineNode, 0, len(symbols))
+	for _, sym := range symbols {
+		nodes = append(nodes, &outlineNode{
+			Name:  sym.Name,
+			Kind:  sym.Kind,
+			Range: sym.Location.Range,
+		})
+	}
+
+	// Sort by start position, with enclosing (larger) ranges first
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i].Range, nodes[j].Range
+		if a.Start != b.Start {
+			return positionBefore(a.Start, b.Start)
+		}
+		return positionBefore(b.End, a.End)
+	})
+
+	var roots []*outlineNode
+	var stack []*outlineNode
+	for _, node := range nodes {
+		for len(stack) > 0 && !rangeContains(stack[len(stack)-1].Range, node.Range) {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, node)
+	}
+
+	return roots
+}
+
+// writeOutline writes one line per symbol, indenting children under their parent
+func writeOutline(result *strings.Builder, nodes []*outlineNode, depth int) {
+	for _, node := range nodes {
+		result.WriteString(strings.Repeat("  ", depth))
+		if kind, ok := protocol.TableKindMap[node.Kind]; ok {
+			result.WriteString(kind)
+			result.WriteString(" ")
+		}
+		result.WriteString(node.Name)
+		if node.Detail != "" {
+			result.WriteString(": ")
+			result.WriteString(strings.Join(strings.Fields(node.Detail), " "))
+		}
+		if node.Range.Start.Line == node.Range.End.Line {
+			fmt.Fprintf(result, " L%d\n", node.Range.Start.Line+1)
+		} else {
+			fmt.Fprintf(result, " L%d-L%d\n", node.Range.Start.Line+1, node.Range.End.Line+1)
+		}
+		writeOutline(result, node.Children, depth+1)
+	}
+}
+
+// sortOutline orders symbols by their position in the file
+func sortOutline(nodes []*outlineNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return positionBefore(nodes[i].Range.Start, nodes[j].Range.Start)
+	})
+}
+
+func positionBefore(a, b protocol.Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Character < b.Character
+}
+
+// rangeContains reports whether inner lies entirely within outer
+func rangeContains(outer, inner protocol.Range) bool {
+	return !positionBefore(inner.Start, outer.Start) && !positionBefore(outer.End, inner.End)
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func lineRange(start, end uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: start},
+		End:   protocol.Position{Line: end, Character: 1},
+	}
+}
+
+func renderOutline(nodes []*outlineNode) string {
+	var result strings.Builder
+	writeOutline(&result, nodes, 0)
+	return result.String()
+}
+
+func TestOutlineFromDocumentSymbols(t *testing.T) {
+	symbols := []protocol.DocumentSymbol{
+		{
+			Name:   "(*SharedStruct).Method",
+			Detail: "func() string",
+			Kind:   protocol.Method,
+			Range:  lineRange(13, 15),
+		},
+		{
+			Name:   "SharedStruct",
+			Detail: "struct{...}",
+			Kind:   protocol.Struct,
+			Range:  lineRange(5, 10),
+			Children: []protocol.DocumentSymbol{
+				{Name: "Name", Detail: "string", Kind: protocol.Field, Range: lineRange(7, 7)},
+				{Name: "ID", Detail: "int", Kind: protocol.Field, Range: lineRange(6, 6)},
+			},
+		},
+	}
+
+	expected := "Struct SharedStruct: struct{...} L6-L11\n" +
+		"  Field ID: int L7\n" +
+		"  Field Name: string L8\n" +
+		"Method (*SharedStruct).Method: func() string L14-L16\n"
+
+	assert.Equal(t, expected, renderOutline(outlineFromDocumentSymbols(symbols)))
+}
+
+func TestOutlineFromSymbolInformation(t *testing.T) {
+	symbol := func(name string, kind protocol.SymbolKind, container string, rng protocol.Range) protocol.SymbolInformation {
+		return protocol.SymbolInformation{
+			Name:          name,
+			Kind:          kind,
+			ContainerName: container,
+			Location:      protocol.Location{URI: "file:///workspace/main.py", Range: rng},
+		}
+	}
+
+	symbols := []protocol.SymbolInformation{
+		symbol("helper", protocol.Function, "", lineRange(20, 22)),
+		symbol("method", protocol.Method, "MyClass", lineRange(3, 5)),
+		symbol("MyClass", protocol.Class, "", lineRange(0, 10)),
+		symbol("inner", protocol.Variable, "method", lineRange(4, 4)),
+	}
+
+	expected := "Class MyClass L1-L11\n" +
+		"  Method method L4-L6\n" +
+		"    Variable inner L5\n" +
+		"Function helper L21-L23\n"
+
+	assert.Equal(t, expected, renderOutline(outlineFromSymbolInformation(symbols)))
+}
@@ -19,10 +19,13 @@ type TextEdit struct {
 	NewText   string `json:"newText" jsonschema:"description=Replacement text. Replace with the new text. Leave blank to remove lines."`
 }
 
+// ApplyTextEdits replaces lines of filePath. client is the language server
+// for the file, told about the change, or nil if no server handles it.
 func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, edits []TextEdit) (string, error) {
-	err := client.OpenFile(ctx, filePath)
-	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+	if client != nil {
+		if err := client.OpenFile(ctx, filePath); err != nil {
+			return "", fmt.Errorf("could not open file: %v", err)
+		}
 	}
 
 	// Create a sorted copy of edits for reporting
@@ -78,13 +81,16 @@ func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, ed
 		},
 	}
 
-	if err := utilities.ApplyWorkspaceEdit(edit); err != nil {
+	// getRange counts characters in bytes, not in the server's encoding
+	if err := utilities.ApplyWorkspaceEdit(edit, protocol.UTF8); err != nil {
 		return "", fmt.Errorf("failed to apply text edits: %v", err)
 	}
 
 	// Notify the language server that the file contents changed on disk
-	if err := client.NotifyChange(ctx, filePath); err != nil {
-		toolsLogger.Warn("Failed to notify language server of change to %s: %v", filePath, err)
+	if client != nil {
+		if err := client.NotifyChange(ctx, filePath); err != nil {
+			toolsLogger.Warn("Failed to notify language server of change to %s: %v", filePath, err)
+		}
 	}
 
 	return fmt.Sprintf("Successfully applied text edits. %d lines removed, %d lines added.", linesRemovedSorted, linesAddedSorted), nil
@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTextEdits_WithoutLanguageServer(t *testing.T) {
+	// Files such as READMEs have no language server to tell about the edit
+	path := filepath.Join(t.TempDir(), "README.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Title\nold line\nlast line\n"), 0644))
+
+	result, err := ApplyTextEdits(context.Background(), nil, path, []TextEdit{
+		{StartLine: 2, EndLine: 2, NewText: "new line"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result, "1 lines removed, 1 lines added")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Title\nnew line\nlast line\n", string(content))
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// editorConfigProperties reads the .editorconfig files that apply to filePath
+// and returns the merged properties. Files closer to filePath take precedence,
+// and the search stops at a file declaring root = true.
+func editorConfigProperties(filePath string) map[string]string {
+	var configs []string
+	dir := filepath.Dir(filePath)
+	for {
+		configPath := filepath.Join(dir, ".editorconfig")
+		if _, err := os.Stat(configPath); err == nil {
+			configs = append(configs, configPath)
+			if isEditorConfigRoot(configPath) {
+				break
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	// Apply the outermost file first so that nearer files override it
+	properties := make(map[string]string)
+	for i := len(configs) - 1; i >= 0; i-- {
+		applyEditorConfig(configs[i], filePath, properties)
+	}
+	return properties
+}
+
+// isEditorConfigRoot reports whether the preamble of an .editorconfig file sets root = true
+func isEditorConfigRoot(configPath string) bool {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			toolsLogger.Warn("Failed to close %s: %v", configPath, err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			return false
+		}
+		key, value, ok := parseEditorConfigLine(line)
+		if ok && key == "root" {
+			return value == "true"
+		}
+	}
+	return false
+}
+
+// applyEditorConfig merges the properties of every section in configPath that matches filePath
+func applyEditorConfig(configPath string, filePath string, properties map[string]string) {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			toolsLogger.Warn("Failed to close %s: %v", configPath, err)
+		}
+	}()
+
+	relPath, err := filepath.Rel(filepath.Dir(configPath), filePath)
+	if err != nil {
+		return
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	matches := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			matches = editorConfigSectionMatches(line[1:len(line)-1], relPath)
+			continue
+		}
+		if !matches {
+			continue
+		}
+		if key, value, ok := parseEditorConfigLine(line); ok {
+			properties[key] = value
+		}
+	}
+}
+
+// editorConfigSectionMatches matches a section glob against a path relative to the .editorconfig file.
+// Globs without a slash match the file name in any directory.
+func editorConfigSectionMatches(pattern string, relPath string) bool {
+	if strings.HasPrefix(pattern, "/") {
+		pattern = pattern[1:]
+	} else if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	match, err := doublestar.Match(pattern, relPath)
+	return err == nil && match
+}
+
+// parseEditorConfigLine splits a "key = value" line, ignoring comments and blank lines
+func parseEditorConfigLine(line string) (string, string, bool) {
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+		return "", "", false
+	}
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	return strings.ToLower(strings.TrimSpace(key)), strings.ToLower(strings.TrimSpace(value)), true
+}
+
+// editorConfigInt parses a numeric editorconfig property
+func editorConfigInt(properties map[string]string, key string) (int, bool) {
+	value, ok := properties[key]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+	"github.com/vector67/mcp-language-server/internal/utilities"
+)
+
+// defaultTabSize is used when neither .editorconfig nor the file itself
+// indicate an indentation width
+const defaultTabSize = 4
+
+// FormatDocument formats a whole file using the language server's formatter
+func FormatDocument(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	options, err := getFormattingOptions(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	edits, err := client.Formatting(ctx, protocol.DocumentFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+		Options: options,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to format document: %w", err)
+	}
+
+	return applyFormattingEdits(ctx, client, filePath, edits)
+}
+
+// FormatRange formats the lines between startLine and endLine (1-indexed, inclusive)
+// using the language server's formatter
+func FormatRange(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	if endLine < startLine {
+		return "", fmt.Errorf("end line %d is before start line %d", endLine, startLine)
+	}
+
+	rng, err := getRange(startLine, endLine, filePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid position: %v", err)
+	}
+
+	options, err := getFormattingOptions(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	edits, err := client.RangeFormatting(ctx, protocol.DocumentRangeFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+		Range:   rng,
+		Options: options,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to format range: %w", err)
+	}
+
+	return applyFormattingEdits(ctx, client, filePath, edits)
+}
+
+// applyFormattingEdits writes the formatter's edits to disk, notifies the server
+// and reports how many lines changed
+func applyFormattingEdits(ctx context.Context, client *lsp.Client, filePath string, edits []protocol.TextEdit) (string, error) {
+	if len(edits) == 0 {
+		return fmt.Sprintf("%s is already formatted. 0 lines changed.", filePath), nil
+	}
+
+	if err := utilities.ApplyTextEdits(protocol.DocumentUri("file://"+filePath), edits, client.PositionEncoding()); err != nil {
+		return "", fmt.Errorf("failed to apply formatting edits: %v", err)
+	}
+
+	// Notify the language server that the file contents changed on disk
+	if err := client.NotifyChange(ctx, filePath); err != nil {
+		toolsLogger.Warn("Failed to notify language server of change to %s: %v", filePath, err)
+	}
+
+	changed := countChangedLines(edits)
+	return fmt.Sprintf("Successfully formatted %s. %d lines changed.", filePath, changed), nil
+}
+
+// getFormattingOptions builds FormattingOptions from .editorconfig, falling back
+// to the indentation already used in the file
+func getFormattingOptions(filePath string) (protocol.FormattingOptions, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return protocol.FormattingOptions{}, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	insertSpaces, tabSize := detectIndentation(string(content))
+	options := protocol.FormattingOptions{
+		TabSize:      uint32(tabSize),
+		InsertSpaces: insertSpaces,
+	}
+
+	properties := editorConfigProperties(filePath)
+	switch properties["indent_style"] {
+	case "space":
+		options.InsertSpaces = true
+	case "tab":
+		options.InsertSpaces = false
+	}
+	if size, ok := editorConfigInt(properties, "indent_size"); ok {
+		options.TabSize = uint32(size)
+	} else if size, ok := editorConfigInt(properties, "tab_width"); ok {
+		options.TabSize = uint32(size)
+	}
+	options.TrimTrailingWhitespace = properties["trim_trailing_whitespace"] == "true"
+	options.InsertFinalNewline = properties["insert_final_newline"] == "true"
+
+	return options, nil
+}
+
+// detectIndentation guesses whether a file is indented with spaces and how wide
+// an indentation level is, based on the leading whitespace of its lines
+func detectIndentation(content string) (bool, int) {
+	tabLines := 0
+	spaceLines := 0
+	widths := make(map[int]int)
+
+	previousWidth := 0
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			tabLines++
+			previousWidth = 0
+		case strings.HasPrefix(line, " "):
+			spaceLines++
+			width := len(line) - len(strings.TrimLeft(line, " "))
+			// Count the change in indentation between consecutive lines, which
+			// is more robust than the absolute width for nested blocks
+			if delta := width - previousWidth; delta > 1 {
+				widths[delta]++
+			}
+			previousWidth = width
+		default:
+			previousWidth = 0
+		}
+	}
+
+	if tabLines >= spaceLines {
+		return false, defaultTabSize
+	}
+
+	tabSize := defaultTabSize
+	best := 0
+	for width, count := range widths {
+		if count > best || (count == best && width < tabSize) {
+			tabSize = width
+			best = count
+		}
+	}
+	return true, tabSize
+}
+
+// countChangedLines counts the lines touched by a set of edits. Edits that
+// touch the same lines are grouped into hunks, and each hunk counts as the
+// larger of the lines it replaces and the lines it produces.
+func countChangedLines(edits []protocol.TextEdit) int {
+	sorted := slices.Clone(edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return positionBefore(sorted[i].Range.Start, sorted[j].Range.Start)
+	})
+
+	changed := 0
+	// Original lines [hunkStart, hunkEnd) covered by the current hunk, and the
+	// net number of lines its edits add
+	hunkStart, hunkEnd, delta := -1, -1, 0
+	for _, edit := range sorted {
+		start := int(edit.Range.Start.Line)
+		end := int(edit.Range.End.Line) + 1
+		removedBreaks := int(edit.Range.End.Line - edit.Range.Start.Line)
+		addedBreaks := strings.Count(edit.NewText, "\n")
+
+		// An edit that inserts or removes whole lines leaves the line it
+		// ends on untouched
+		wholeLines := edit.Range.Start.Character == 0 && edit.Range.End.Character == 0 &&
+			(removedBreaks > 0 || addedBreaks > 0) &&
+			(edit.NewText == "" || strings.HasSuffix(edit.NewText, "\n"))
+		if wholeLines {
+			end--
+		}
+
+		if hunkStart >= 0 && start < hunkEnd {
+			hunkEnd = max(hunkEnd, end)
+		} else {
+			if hunkStart >= 0 {
+				changed += max(hunkEnd-hunkStart, hunkEnd-hunkStart+delta)
+			}
+			hunkStart, hunkEnd, delta = start, end, 0
+		}
+		delta += addedBreaks - removedBreaks
+	}
+	if hunkStart >= 0 {
+		changed += max(hunkEnd-hunkStart, hunkEnd-hunkStart+delta)
+	}
+	return changed
+}
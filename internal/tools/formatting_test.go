@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func TestCountChangedLines(t *testing.T) {
+	edit := func(startLine, startChar, endLine, endChar uint32, newText string) protocol.TextEdit {
+		return protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: startLine, Character: startChar},
+				End:   protocol.Position{Line: endLine, Character: endChar},
+			},
+			NewText: newText,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		edits    []protocol.TextEdit
+		expected int
+	}{
+		{
+			name:     "no edits",
+			edits:    nil,
+			expected: 0,
+		},
+		{
+			name:     "single modified line",
+			edits:    []protocol.TextEdit{edit(1, 0, 1, 1, "B")},
+			expected: 1,
+		},
+		{
+			name:     "inserted line",
+			edits:    []protocol.TextEdit{edit(1, 0, 1, 0, "b\n")},
+			expected: 1,
+		},
+		{
+			name:     "deleted lines",
+			edits:    []protocol.TextEdit{edit(1, 0, 3, 0, "")},
+			expected: 2,
+		},
+		{
+			name:     "separate hunks",
+			edits:    []protocol.TextEdit{edit(3, 0, 3, 1, "D"), edit(0, 0, 0, 1, "A")},
+			expected: 2,
+		},
+		{
+			name:     "several edits on one line",
+			edits:    []protocol.TextEdit{edit(2, 0, 2, 2, "\t"), edit(2, 5, 2, 6, " = ")},
+			expected: 1,
+		},
+		{
+			name:     "reindented block",
+			edits:    []protocol.TextEdit{edit(1, 0, 1, 2, "\t"), edit(2, 0, 2, 2, "\t")},
+			expected: 2,
+		},
+		{
+			name:     "joined lines",
+			edits:    []protocol.TextEdit{edit(0, 10, 2, 1, " }")},
+			expected: 3,
+		},
+		{
+			name:     "split line",
+			edits:    []protocol.TextEdit{edit(4, 8, 4, 9, "\n\t")},
+			expected: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, countChangedLines(tc.edits))
+		})
+	}
+}
+
+func TestDetectIndentation(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		insertSpaces bool
+		tabSize      int
+	}{
+		{
+			name:         "tabs",
+			content:      "func f() {\n\tif x {\n\t\treturn\n\t}\n}\n",
+			insertSpaces: false,
+			tabSize:      defaultTabSize,
+		},
+		{
+			name:         "two spaces",
+			content:      "function f() {\n  if (x) {\n    return;\n  }\n}\n",
+			insertSpaces: true,
+			tabSize:      2,
+		},
+		{
+			name:         "four spaces",
+			content:      "def f():\n    if x:\n        return\n    pass\n",
+			insertSpaces: true,
+			tabSize:      4,
+		},
+		{
+			name:         "no indentation",
+			content:      "a\nb\n",
+			insertSpaces: false,
+			tabSize:      defaultTabSize,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			insertSpaces, tabSize := detectIndentation(tc.content)
+			assert.Equal(t, tc.insertSpaces, insertSpaces)
+			assert.Equal(t, tc.tabSize, tabSize)
+		})
+	}
+}
+
+func TestGetFormattingOptions_EditorConfig(t *testing.T) {
+	root := t.TempDir()
+	subDir := filepath.Join(root, "web")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(root, ".editorconfig"),
+		"root = true\n\n[*]\nindent_style = tab\ninsert_final_newline = true\n\n[*.{ts,js}]\nindent_style = space\nindent_size = 2\n")
+	writeTestFile(t, filepath.Join(subDir, ".editorconfig"),
+		"[*.ts]\nindent_size = 4\ntrim_trailing_whitespace = true\n")
+
+	tsFile := filepath.Join(subDir, "main.ts")
+	writeTestFile(t, tsFile, "const x = 1;\n")
+	goFile := filepath.Join(root, "main.go")
+	writeTestFile(t, goFile, "package main\n")
+
+	options, err := getFormattingOptions(tsFile)
+	if err != nil {
+		t.Fatalf("getFormattingOptions failed: %v", err)
+	}
+	assert.True(t, options.InsertSpaces)
+	assert.Equal(t, uint32(4), options.TabSize)
+	assert.True(t, options.TrimTrailingWhitespace)
+	assert.True(t, options.InsertFinalNewline)
+
+	options, err = getFormattingOptions(goFile)
+	if err != nil {
+		t.Fatalf("getFormattingOptions failed: %v", err)
+	}
+	assert.False(t, options.InsertSpaces)
+	assert.False(t, options.TrimTrailingWhitespace)
+	assert.True(t, options.InsertFinalNewline)
+}
+
+func TestGetFormattingOptions_FallsBackToFileIndentation(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ".editorconfig"), "root = true\n")
+
+	pyFile := filepath.Join(root, "main.py")
+	writeTestFile(t, pyFile, "def f():\n  return 1\n")
+
+	options, err := getFormattingOptions(pyFile)
+	if err != nil {
+		t.Fatalf("getFormattingOptions failed: %v", err)
+	}
+	assert.True(t, options.InsertSpaces)
+	assert.Equal(t, uint32(2), options.TabSize)
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
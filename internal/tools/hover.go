@@ -20,10 +20,9 @@ func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line
 
 	params := protocol.HoverParams{}
 
-	// Convert 1-indexed line/column to 0-indexed for LSP protocol
-	position := protocol.Position{
-		Line:      uint32(line - 1),
-		Character: uint32(column - 1),
+	position, err := positionAt(filePath, line, column, client.PositionEncoding())
+	if err != nil {
+		return "", err
 	}
 	uri := protocol.DocumentUri("file://" + filePath)
 	params.TextDocument = protocol.TextDocumentIdentifier{
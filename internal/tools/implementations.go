@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// FindImplementations returns the source of every concrete type or method that
+// implements the named interface, abstract class or method
+func FindImplementations(ctx context.Context, clients []*lsp.Client, symbolName string) (string, error) {
+	symbolName, results, err := QuerySymbol(ctx, clients, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	// Each server reads the source of the implementations it found
+	locations := make(map[*lsp.Client][]protocol.Location)
+	found := 0
+	for _, result := range results {
+		client, symbol := result.Client, result.Symbol
+		if !symbolMatches(symbol, symbolName) {
+			continue
+		}
+
+		loc := symbol.GetLocation()
+		impls, err := getImplementationLocations(ctx, client, loc.URI, loc.Range.Start)
+		if err != nil {
+			toolsLogger.Error("Error getting implementations of %s: %v", symbol.GetName(), err)
+			continue
+		}
+		locations[client] = append(locations[client], impls...)
+		found += len(impls)
+	}
+
+	if found == 0 {
+		return fmt.Sprintf("No implementations found for %s", symbolName), nil
+	}
+
+	var result strings.Builder
+	for _, client := range clients {
+		if len(locations[client]) > 0 {
+			result.WriteString(formatLocationDefinitions(ctx, client, locations[client]))
+		}
+	}
+	return result.String(), nil
+}
+
+// FindImplementationsAtPosition returns the source of every implementation of
+// the symbol at the given position (1-indexed line and column)
+func FindImplementationsAtPosition(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	uri := protocol.DocumentUri("file://" + filePath)
+	position, err := positionAt(filePath, line, column, client.PositionEncoding())
+	if err != nil {
+		return "", err
+	}
+
+	locations, err := getImplementationLocations(ctx, client, uri, position)
+	if err != nil {
+		return "", err
+	}
+
+	if len(locations) == 0 {
+		return fmt.Sprintf("No implementations found at %s L%d:C%d", filePath, line, column), nil
+	}
+
+	return formatLocationDefinitions(ctx, client, locations), nil
+}
+
+// getImplementationLocations sends a textDocument/implementation request for a position
+func getImplementationLocations(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, position protocol.Position) ([]protocol.Location, error) {
+	err := client.OpenFile(ctx, uri.Path())
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	result, err := client.Implementation(ctx, protocol.ImplementationParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: uri,
+			},
+			Position: position,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get implementations: %v", err)
+	}
+
+	return result.Locations()
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/protocol"
+	"github.com/vector67/mcp-language-server/internal/utilities"
 )
 
 type match struct {
@@ -131,7 +132,7 @@ func GetFullDefinition(ctx context.Context, client *lsp.Client, startLocation pr
 									if len(bracketStack) == 0 {
 										// Found matching bracket - update range
 										symbolRange.End.Line = lineNum
-										symbolRange.End.Character = uint32(pos + 1)
+										symbolRange.End.Character = utilities.ByteOffsetToCharacter(line, pos+1, client.PositionEncoding())
 										goto foundClosing
 									}
 								}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+	"github.com/vector67/mcp-language-server/internal/utilities"
+)
+
+// ReadWithHints returns a line range of a file with the server's inlay hints,
+// such as inferred types and parameter names, inlined as «hint». Lines are
+// 1-indexed and inclusive; an endLine of 0 reads to the end of the file.
+func ReadWithHints(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int) (string, error) {
+	// Open the file if not already open
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := strings.Split(string(content), "\n")
+
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine <= 0 || endLine > len(lines) {
+		endLine = len(lines)
+	}
+	if startLine > endLine {
+		return "", fmt.Errorf("invalid line range: %d-%d (file has %d lines)", startLine, endLine, len(lines))
+	}
+
+	hints, err := client.InlayHint(ctx, protocol.InlayHintParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(startLine - 1)},
+			End:   protocol.Position{Line: uint32(endLine - 1), Character: uint32(len(lines[endLine-1]))},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get inlay hints: %v", err)
+	}
+
+	// Labels are never resolved lazily, but some servers send hints with an
+	// empty label and fill it in on resolve
+	if client.SupportsInlayHintResolve() {
+		for i, hint := range hints {
+			if hint.LabelText() != "" || hint.Data == nil {
+				continue
+			}
+			resolved, err := client.Resolve(ctx, hint)
+			if err != nil {
+				toolsLogger.Warn("Failed to resolve inlay hint at %d:%d: %v", hint.Position.Line+1, hint.Position.Character+1, err)
+				continue
+			}
+			hints[i] = resolved
+		}
+	}
+
+	annotated := annotateLines(lines, hints, startLine-1, endLine-1, client.PositionEncoding())
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("File: %s\nLines: %d-%d\n", filePath, startLine, endLine))
+	if len(hints) == 0 {
+		result.WriteString("No inlay hints returned for this range\n")
+	}
+	result.WriteString("\n")
+	result.WriteString(FormatLinesWithRanges(annotated, []LineRange{{Start: startLine - 1, End: endLine - 1}}))
+
+	return result.String(), nil
+}
+
+// annotateLines returns a copy of lines with the hints between first and last
+// (0-indexed, inclusive) inserted at their positions, which are counted in
+// code units of encoding
+func annotateLines(lines []string, hints []protocol.InlayHint, first, last int, encoding protocol.PositionEncodingKind) []string {
+	byLine := make(map[int][]protocol.InlayHint)
+	for _, hint := range hints {
+		line := int(hint.Position.Line)
+		if line < first || line > last || hint.LabelText() == "" {
+			continue
+		}
+		byLine[line] = append(byLine[line], hint)
+	}
+
+	annotated := make([]string, len(lines))
+	copy(annotated, lines)
+	for line, lineHints := range byLine {
+		annotated[line] = annotateLine(lines[line], lineHints, encoding)
+	}
+	return annotated
+}
+
+// annotateLine inserts hints into a single line. Hints at the same position
+// keep the order the server sent them in.
+func annotateLine(line string, hints []protocol.InlayHint, encoding protocol.PositionEncodingKind) string {
+	sort.SliceStable(hints, func(i, j int) bool {
+		return hints[i].Position.Character < hints[j].Position.Character
+	})
+
+	var result strings.Builder
+	last := 0
+	for _, hint := range hints {
+		offset := utilities.CharacterToByteOffset(line, hint.Position.Character, encoding)
+		if offset < last {
+			offset = last
+		}
+		result.WriteString(line[last:offset])
+		last = offset
+
+		if hint.PaddingLeft {
+			result.WriteString(" ")
+		}
+		result.WriteString("«" + hint.LabelText() + "»")
+		if hint.PaddingRight {
+			result.WriteString(" ")
+		}
+	}
+	result.WriteString(line[last:])
+	return result.String()
+}
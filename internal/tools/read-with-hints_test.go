@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func TestAnnotateLine(t *testing.T) {
+	hint := func(character uint32, label string, left, right bool) protocol.InlayHint {
+		return protocol.InlayHint{
+			Position:     protocol.Position{Character: character},
+			Label:        []protocol.InlayHintLabelPart{{Value: label}},
+			PaddingLeft:  left,
+			PaddingRight: right,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		line     string
+		hints    []protocol.InlayHint
+		expected string
+	}{
+		{
+			name:     "type hint",
+			line:     "\tx := compute()",
+			hints:    []protocol.InlayHint{hint(2, ": int", false, false)},
+			expected: "\tx«: int» := compute()",
+		},
+		{
+			name:     "parameter hints out of order with padding",
+			line:     "\tadd(1, 2)",
+			hints:    []protocol.InlayHint{hint(8, "b:", false, true), hint(5, "a:", false, true)},
+			expected: "\tadd(«a:» 1, «b:» 2)",
+		},
+		{
+			name:     "elided lifetimes",
+			line:     "fn f(x: &str) -> &str",
+			hints:    []protocol.InlayHint{hint(9, "'0 ", false, false), hint(4, "<'0>", false, false), hint(18, "'0 ", false, false)},
+			expected: "fn f«<'0>»(x: &«'0 »str) -> &«'0 »str",
+		},
+		{
+			name:     "hints at the same position keep their order",
+			line:     "f(x)",
+			hints:    []protocol.InlayHint{hint(2, "first", false, false), hint(2, "second", false, false)},
+			expected: "f(«first»«second»x)",
+		},
+		{
+			name:     "utf-16 positions after multi-byte characters",
+			line:     `let s = "héllo"; let n = s.len();`,
+			hints:    []protocol.InlayHint{hint(22, ": usize", false, false)},
+			expected: `let s = "héllo"; let n«: usize» = s.len();`,
+		},
+		{
+			name:     "hint at end of line",
+			line:     "let v = make()",
+			hints:    []protocol.InlayHint{hint(14, "Vec<u8>", true, false)},
+			expected: "let v = make() «Vec<u8>»",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, annotateLine(tt.line, tt.hints, protocol.UTF16))
+		})
+	}
+}
+
+func TestAnnotateLines(t *testing.T) {
+	lines := []string{"a := 1", "b := 2", "c := 3"}
+	hints := []protocol.InlayHint{
+		{Position: protocol.Position{Line: 0, Character: 1}, Label: []protocol.InlayHintLabelPart{{Value: ": int"}}},
+		{Position: protocol.Position{Line: 1, Character: 1}, Label: []protocol.InlayHintLabelPart{{Value: ": int"}}},
+		{Position: protocol.Position{Line: 2, Character: 1}},
+	}
+
+	annotated := annotateLines(lines, hints, 1, 2, protocol.UTF16)
+	assert.Equal(t, []string{"a := 1", "b«: int» := 2", "c := 3"}, annotated)
+	assert.Equal(t, "a := 1", lines[0], "input lines must not be modified")
+}
@@ -12,7 +12,7 @@ import (
 	"github.com/vector67/mcp-language-server/internal/protocol"
 )
 
-func FindReferences(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
+func FindReferences(ctx context.Context, clients []*lsp.Client, symbolName string) (string, error) {
 	// Get context lines from environment variable
 	contextLines := 5
 	if envLines := os.Getenv("LSP_CONTEXT_LINES"); envLines != "" {
@@ -22,13 +22,14 @@ func FindReferences(ctx context.Context, client *lsp.Client, symbolName string)
 	}
 
 	// First get the symbol location like ReadDefinition does
-	symbolName, results, err := QuerySymbol(ctx, client, symbolName)
+	symbolName, results, err := QuerySymbol(ctx, clients, symbolName)
 	if err != nil {
 		return "", err
 	}
 
 	var allReferences []string
-	for _, symbol := range results {
+	for _, result := range results {
+		client, symbol := result.Client, result.Symbol
 		// Handle different matching strategies based on the search term
 		if strings.Contains(symbolName, ".") {
 			// For qualified names like "Type.Method", check for various matches
@@ -20,11 +20,10 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
 
-	// Convert 1-indexed line/column to 0-indexed for LSP protocol
 	uri := protocol.DocumentUri("file://" + filePath)
-	position := protocol.Position{
-		Line:      uint32(line - 1),
-		Character: uint32(column - 1),
+	position, err := positionAt(filePath, line, column, client.PositionEncoding())
+	if err != nil {
+		return "", err
 	}
 
 	// Create the rename parameters
@@ -36,8 +35,22 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 		NewName:  newName,
 	}
 
-	// Skip the PrepareRename check as it might not be supported by all language servers
-	// Execute the rename directly
+	// Ask servers that support it whether the symbol can be renamed, so an
+	// invalid position gets a clear error instead of an empty edit
+	if client.SupportsPrepareRename() {
+		prepared, err := client.PrepareRename(ctx, protocol.PrepareRenameParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: params.TextDocument,
+				Position:     position,
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to rename symbol: cannot rename at L%d:C%d: %v", line, column, err)
+		}
+		if prepared.Value == nil {
+			return "", fmt.Errorf("failed to rename symbol: there is no symbol that can be renamed at L%d:C%d", line, column)
+		}
+	}
 
 	// Execute the rename operation
 	workspaceEdit, err := client.Rename(ctx, params)
@@ -67,7 +80,7 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 			var locs strings.Builder
 			for i, change := range edits {
 				locs.WriteString(
-					fmt.Sprintf("L%d:C%d", change.Range.Start.Line+1, change.Range.Start.Character+1),
+					fmt.Sprintf("L%d:C%d", change.Range.Start.Line+1, columnAt(strings.TrimPrefix(string(uri), "file://"), change.Range.Start, client.PositionEncoding())),
 				)
 				if i != len(edits)-1 {
 					locs.WriteString(", ")
@@ -87,7 +100,8 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 			for i, edit := range change.TextDocumentEdit.Edits {
 				textEdit, err := edit.AsTextEdit()
 				if err == nil {
-					locs.WriteString(fmt.Sprintf("L%d:C%d", textEdit.Range.Start.Line+1, textEdit.Range.Start.Character+1))
+					path := strings.TrimPrefix(string(change.TextDocumentEdit.TextDocument.URI), "file://")
+					locs.WriteString(fmt.Sprintf("L%d:C%d", textEdit.Range.Start.Line+1, columnAt(path, textEdit.Range.Start, client.PositionEncoding())))
 					if i != len(change.TextDocumentEdit.Edits)-1 {
 						locs.WriteString(", ")
 					}
@@ -113,12 +127,12 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 	}
 
 	// Apply the workspace edit to files:workspaceEdit
-	if err := utilities.ApplyWorkspaceEdit(workspaceEdit); err != nil {
+	if err := utilities.ApplyWorkspaceEdit(workspaceEdit, client.PositionEncoding()); err != nil {
 		return "", fmt.Errorf("failed to apply changes: %v", err)
 	}
 
 	// Notify the language server that file contents changed on disk
-	for _, path := range AffectedFiles(workspaceEdit) {
+	for _, path := range utilities.AffectedFiles(workspaceEdit) {
 		if err := client.NotifyChange(ctx, path); err != nil {
 			toolsLogger.Warn("Failed to notify language server of change to %s: %v", path, err)
 		}
@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// GetSignatureHelp lists every overload of the call at the given position
+// (1-indexed line and column), with the active parameter highlighted
+func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	position, err := positionAt(filePath, line, column, client.PositionEncoding())
+	if err != nil {
+		return "", err
+	}
+
+	var help signatureHelp
+	err = client.Call(ctx, "textDocument/signatureHelp", protocol.SignatureHelpParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: position,
+		},
+		Context: &protocol.SignatureHelpContext{
+			TriggerKind: protocol.SigInvoked,
+		},
+	}, &help)
+	if err != nil {
+		return "", fmt.Errorf("failed to get signature help: %v", err)
+	}
+
+	if len(help.Signatures) == 0 {
+		return fmt.Sprintf("No signature help available at %s L%d:C%d", filePath, line, column), nil
+	}
+
+	return formatSignatureHelp(help), nil
+}
+
+// signatureHelp is a protocol.SignatureHelp that keeps each signature's own
+// activeParameter as sent. protocol.SignatureInformation decodes a missing one
+// as 0, which is also the index of the first parameter.
+type signatureHelp struct {
+	protocol.SignatureHelp
+	Signatures []signatureInformation `json:"signatures"`
+}
+
+type signatureInformation struct {
+	protocol.SignatureInformation
+	// Absent to use the help's active parameter, null for none
+	ActiveParameter json.RawMessage `json:"activeParameter"`
+}
+
+// activeParameter returns the index of the signature's active parameter, or
+// false if none is active
+func (sig signatureInformation) activeParameter(help signatureHelp) (uint32, bool) {
+	if sig.ActiveParameter == nil {
+		return help.ActiveParameter, true
+	}
+	var index *uint32
+	if err := json.Unmarshal(sig.ActiveParameter, &index); err != nil || index == nil {
+		return 0, false
+	}
+	return *index, true
+}
+
+// formatSignatureHelp renders each signature with its documentation and parameters.
+// The active parameter is wrapped in ** in the signature label and marked in the list.
+func formatSignatureHelp(help signatureHelp) string {
+	var result strings.Builder
+	for i, sig := range help.Signatures {
+		isActive := uint32(i) == help.ActiveSignature
+		// Only the active signature's parameter is highlighted
+		activeParam, hasActiveParam := sig.activeParameter(help)
+		hasActiveParam = hasActiveParam && isActive
+
+		label := sig.Label
+		if hasActiveParam && int(activeParam) < len(sig.Parameters) {
+			if start, end, ok := parameterLabelOffsets(sig.Label, sig.Parameters[activeParam]); ok {
+				label = sig.Label[:start] + "**" + sig.Label[start:end] + "**" + sig.Label[end:]
+			}
+		}
+
+		result.WriteString(fmt.Sprintf("[%d] %s", i+1, label))
+		if isActive && len(help.Signatures) > 1 {
+			result.WriteString(" (active)")
+		}
+		result.WriteString("\n")
+
+		if sig.Documentation != nil {
+			if doc := markupToPlainText(sig.Documentation.Value); doc != "" {
+				result.WriteString(indentLines(doc, "    "))
+			}
+		}
+
+		if len(sig.Parameters) > 0 {
+			result.WriteString("    Parameters:\n")
+		}
+		for j, param := range sig.Parameters {
+			marker := "-"
+			if hasActiveParam && uint32(j) == activeParam {
+				marker = ">"
+			}
+			result.WriteString(fmt.Sprintf("      %s %s", marker, parameterLabel(sig.Label, param)))
+			if param.Documentation != nil {
+				if doc := markupToPlainText(param.Documentation.Value); doc != "" {
+					result.WriteString(": ")
+					result.WriteString(strings.Join(strings.Fields(doc), " "))
+				}
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	return result.String()
+}
+
+// parameterLabel returns the text of a parameter's label
+func parameterLabel(signatureLabel string, param protocol.ParameterInformation) string {
+	if start, end, ok := parameterLabelOffsets(signatureLabel, param); ok {
+		return signatureLabel[start:end]
+	}
+	if label, ok := param.Label.Value.(string); ok {
+		return label
+	}
+	return ""
+}
+
+// parameterLabelOffsets finds the byte range of a parameter within the signature
+// label. Labels are either a substring of the signature label or a pair of
+// UTF-16 offsets into it.
+func parameterLabelOffsets(signatureLabel string, param protocol.ParameterInformation) (int, int, bool) {
+	switch v := param.Label.Value.(type) {
+	case string:
+		if v == "" {
+			return 0, 0, false
+		}
+		// Search after the opening parenthesis so a parameter named like the
+		// function does not match the function name
+		offset := max(strings.Index(signatureLabel, "("), 0)
+		idx := strings.Index(signatureLabel[offset:], v)
+		if idx < 0 {
+			return 0, 0, false
+		}
+		return offset + idx, offset + idx + len(v), true
+	case protocol.Tuple_ParameterInformation_label_Item1:
+		start, ok1 := utf16OffsetToByte(signatureLabel, int(v.Fld0))
+		end, ok2 := utf16OffsetToByte(signatureLabel, int(v.Fld1))
+		if !ok1 || !ok2 || start > end {
+			return 0, 0, false
+		}
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
+// utf16OffsetToByte converts an offset in UTF-16 code units to a byte offset in s
+func utf16OffsetToByte(s string, offset int) (int, bool) {
+	units := 0
+	for i, r := range s {
+		if units >= offset {
+			return i, units == offset
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(s), units == offset
+}
+
+// markupToPlainText flattens documentation that is either a plain string or
+// MarkupContent into plain text, dropping markdown code fences
+func markupToPlainText(value any) string {
+	var text string
+	var kind protocol.MarkupKind
+	switch v := value.(type) {
+	case string:
+		text = v
+	case protocol.MarkupContent:
+		text = v.Value
+		kind = v.Kind
+	default:
+		return ""
+	}
+
+	if kind == protocol.Markdown {
+		var lines []string
+		for _, line := range strings.Split(text, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "```") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		text = strings.Join(lines, "\n")
+	}
+
+	return strings.TrimSpace(text)
+}
+
+// indentLines prefixes every line of text with indent and ends it with a newline
+func indentLines(text string, indent string) string {
+	var result strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			result.WriteString("\n")
+			continue
+		}
+		result.WriteString(indent)
+		result.WriteString(line)
+		result.WriteString("\n")
+	}
+	return result.String()
+}
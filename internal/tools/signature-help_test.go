@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func TestFormatSignatureHelp(t *testing.T) {
+	stringParam := func(label string, doc any) protocol.ParameterInformation {
+		param := protocol.ParameterInformation{Label: protocol.Or_ParameterInformation_label{Value: label}}
+		if doc != nil {
+			param.Documentation = &protocol.Or_ParameterInformation_documentation{Value: doc}
+		}
+		return param
+	}
+
+	help := signatureHelp{
+		SignatureHelp: protocol.SignatureHelp{
+			ActiveSignature: 1,
+			ActiveParameter: 1,
+		},
+		Signatures: []signatureInformation{
+			{SignatureInformation: protocol.SignatureInformation{
+				Label:      "add(a int, b int) int",
+				Parameters: []protocol.ParameterInformation{stringParam("a int", nil), stringParam("b int", nil)},
+			}},
+			{SignatureInformation: protocol.SignatureInformation{
+				Label: "add(a float64, b float64, c float64) float64",
+				Documentation: &protocol.Or_SignatureInformation_documentation{Value: protocol.MarkupContent{
+					Kind:  protocol.Markdown,
+					Value: "Adds three floats.\n\n```go\nadd(1, 2, 3)\n```",
+				}},
+				Parameters: []protocol.ParameterInformation{
+					{Label: protocol.Or_ParameterInformation_label{Value: protocol.Tuple_ParameterInformation_label_Item1{Fld0: 4, Fld1: 13}}},
+					stringParam("b float64", "the second\n  operand"),
+					stringParam("c float64", protocol.MarkupContent{Kind: protocol.PlainText, Value: "the third operand"}),
+				},
+			}},
+		},
+	}
+
+	expected := "[1] add(a int, b int) int\n" +
+		"    Parameters:\n" +
+		"      - a int\n" +
+		"      - b int\n" +
+		"[2] add(a float64, **b float64**, c float64) float64 (active)\n" +
+		"    Adds three floats.\n" +
+		"\n" +
+		"    add(1, 2, 3)\n" +
+		"    Parameters:\n" +
+		"      - a float64\n" +
+		"      > b float64: the second operand\n" +
+		"      - c float64: the third operand\n"
+
+	assert.Equal(t, expected, formatSignatureHelp(help))
+}
+
+func TestParameterLabelOffsets_UTF16(t *testing.T) {
+	// "é" is one UTF-16 code unit but two bytes, "𝔁" is two code units and four bytes
+	label := "f(é int, 𝔁 string)"
+	param := protocol.ParameterInformation{
+		Label: protocol.Or_ParameterInformation_label{Value: protocol.Tuple_ParameterInformation_label_Item1{Fld0: 9, Fld1: 18}},
+	}
+
+	start, end, ok := parameterLabelOffsets(label, param)
+	if !ok {
+		t.Fatalf("expected offsets to resolve")
+	}
+	assert.Equal(t, "𝔁 string", label[start:end])
+}
+
+func TestParameterLabelOffsets_SkipsFunctionName(t *testing.T) {
+	param := protocol.ParameterInformation{Label: protocol.Or_ParameterInformation_label{Value: "x"}}
+
+	start, end, ok := parameterLabelOffsets("x(x int)", param)
+	if !ok {
+		t.Fatalf("expected label to be found")
+	}
+	assert.Equal(t, 2, start)
+	assert.Equal(t, 3, end)
+}
+
+func TestFormatSignatureHelp_SignatureActiveParameter(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		expected  string
+	}{
+		{
+			name:      "Absent",
+			signature: `{"label": "f(a, b)", "parameters": [{"label": "a"}, {"label": "b"}]}`,
+			expected:  "[1] f(a, **b**)\n    Parameters:\n      - a\n      > b\n",
+		},
+		{
+			name:      "ExplicitZero",
+			signature: `{"label": "f(a, b)", "parameters": [{"label": "a"}, {"label": "b"}], "activeParameter": 0}`,
+			expected:  "[1] f(**a**, b)\n    Parameters:\n      > a\n      - b\n",
+		},
+		{
+			name:      "Null",
+			signature: `{"label": "f(a, b)", "parameters": [{"label": "a"}, {"label": "b"}], "activeParameter": null}`,
+			expected:  "[1] f(a, b)\n    Parameters:\n      - a\n      - b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The help's own active parameter is the second one
+			data := `{"signatures": [` + tt.signature + `], "activeSignature": 0, "activeParameter": 1}`
+			var help signatureHelp
+			if err := json.Unmarshal([]byte(data), &help); err != nil {
+				t.Fatalf("failed to decode signature help: %v", err)
+			}
+			assert.Equal(t, tt.expected, formatSignatureHelp(help))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// ReadTypeDefinition returns the source of the type of the symbol at the given
+// position (1-indexed line and column), e.g. the struct a variable holds
+func ReadTypeDefinition(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+
+	position, err := positionAt(filePath, line, column, client.PositionEncoding())
+	if err != nil {
+		return "", err
+	}
+
+	result, err := client.TypeDefinition(ctx, protocol.TypeDefinitionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: position,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get type definition: %v", err)
+	}
+
+	locations, err := result.Locations()
+	if err != nil {
+		return "", err
+	}
+
+	if len(locations) == 0 {
+		return fmt.Sprintf("No type definition found at %s L%d:C%d", filePath, line, column), nil
+	}
+
+	return formatLocationDefinitions(ctx, client, locations), nil
+}
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// Directions accepted by GetTypeHierarchy
+const (
+	TypeHierarchyUp   = "up"
+	TypeHierarchyDown = "down"
+	TypeHierarchyBoth = "both"
+)
+
+// DefaultTypeHierarchyDepth is the number of levels shown when no depth is given
+const DefaultTypeHierarchyDepth = 3
+
+// typeHierarchyFetcher returns the direct supertypes or subtypes of an item
+type typeHierarchyFetcher func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error)
+
+// GetTypeHierarchy renders the supertypes and/or subtypes of a type as a tree,
+// following the hierarchy up to maxDepth levels
+func GetTypeHierarchy(ctx context.Context, clients []*lsp.Client, symbolName string, direction string, maxDepth int) (string, error) {
+	if direction != TypeHierarchyUp && direction != TypeHierarchyDown && direction != TypeHierarchyBoth {
+		return "", fmt.Errorf("invalid direction %q. Valid directions: %s, %s, %s", direction, TypeHierarchyUp, TypeHierarchyDown, TypeHierarchyBoth)
+	}
+	if maxDepth < 1 {
+		return "", fmt.Errorf("depth must be at least 1")
+	}
+
+	symbolName, results, err := QuerySymbol(ctx, clients, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	// After this point we just return errors instead of erroring out
+	var result strings.Builder
+	for _, found := range results {
+		client, symbol := found.Client, found.Symbol
+		if !symbolMatches(symbol, symbolName) {
+			continue
+		}
+
+		supertypes := func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+			return client.Supertypes(ctx, protocol.TypeHierarchySupertypesParams{Item: item})
+		}
+		subtypes := func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+			return client.Subtypes(ctx, protocol.TypeHierarchySubtypesParams{Item: item})
+		}
+
+		loc := symbol.GetLocation()
+		if err := client.OpenFile(ctx, loc.URI.Path()); err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+
+		items, err := client.PrepareTypeHierarchy(ctx, protocol.TypeHierarchyPrepareParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{
+					URI: loc.URI,
+				},
+				Position: loc.Range.Start,
+			},
+		})
+		if err != nil {
+			result.WriteString(fmt.Sprintf("%s: Error: %v\n", symbol.GetName(), err))
+			continue
+		}
+
+		for _, item := range items {
+			result.WriteString("---\n\n")
+			writeTypeHierarchyItem(&result, item, "Name: ", 0)
+			if direction == TypeHierarchyUp || direction == TypeHierarchyBoth {
+				recurseTypeHierarchy(&result, item, "Supertype: ", supertypes, 1, maxDepth, map[string]bool{typeHierarchyKey(item): true})
+			}
+			if direction == TypeHierarchyDown || direction == TypeHierarchyBoth {
+				recurseTypeHierarchy(&result, item, "Subtype: ", subtypes, 1, maxDepth, map[string]bool{typeHierarchyKey(item): true})
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	if result.Len() == 0 {
+		return fmt.Sprintf("No type hierarchy found for %s", symbolName), nil
+	}
+
+	return result.String(), nil
+}
+
+// recurseTypeHierarchy writes the related types of item at the given depth.
+// Types already on the current path are marked as cycles and not expanded again.
+func recurseTypeHierarchy(result *strings.Builder, item protocol.TypeHierarchyItem, label string, fetch typeHierarchyFetcher, depth int, maxDepth int, path map[string]bool) {
+	if depth > maxDepth {
+		return
+	}
+
+	related, err := fetch(item)
+	if err != nil {
+		result.WriteString(strings.Repeat("  ", depth-1))
+		result.WriteString("Error: ")
+		result.WriteString(err.Error())
+		result.WriteRune('\n')
+		return
+	}
+
+	// ensure output is deterministic for tests
+	sort.SliceStable(related, func(i, j int) bool {
+		if related[i].Name != related[j].Name {
+			return related[i].Name < related[j].Name
+		}
+		return related[i].URI < related[j].URI
+	})
+
+	for _, child := range related {
+		key := typeHierarchyKey(child)
+		if path[key] {
+			writeTypeHierarchyItem(result, child, "- "+label, depth)
+			result.WriteString(strings.Repeat("  ", depth))
+			result.WriteString("(cycle)\n")
+			continue
+		}
+
+		writeTypeHierarchyItem(result, child, "- "+label, depth)
+
+		path[key] = true
+		recurseTypeHierarchy(result, child, label, fetch, depth+1, maxDepth, path)
+		delete(path, key)
+	}
+}
+
+// writeTypeHierarchyItem writes a single type as "Name (Kind): detail - file:line"
+func writeTypeHierarchyItem(result *strings.Builder, item protocol.TypeHierarchyItem, label string, depth int) {
+	if depth > 0 {
+		result.WriteString(strings.Repeat("  ", depth-1))
+	}
+	result.WriteString(label)
+	result.WriteString(item.Name)
+	if kind, ok := protocol.TableKindMap[item.Kind]; ok {
+		fmt.Fprintf(result, " (%s)", kind)
+	}
+	if item.Detail != "" {
+		result.WriteString(": ")
+		result.WriteString(item.Detail)
+	}
+	fmt.Fprintf(result, " - %s:%d\n", strings.TrimPrefix(string(item.URI), "file://"), item.SelectionRange.Start.Line+1)
+}
+
+// typeHierarchyKey identifies a type by its name and location
+func typeHierarchyKey(item protocol.TypeHierarchyItem) string {
+	return fmt.Sprintf("%s@%s:%d:%d", item.Name, item.URI, item.SelectionRange.Start.Line, item.SelectionRange.Start.Character)
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func typeItem(name string, line uint32) protocol.TypeHierarchyItem {
+	rng := protocol.Range{
+		Start: protocol.Position{Line: line, Character: 6},
+		End:   protocol.Position{Line: line, Character: 6 + uint32(len(name))},
+	}
+	return protocol.TypeHierarchyItem{
+		Name:           name,
+		Kind:           protocol.Class,
+		URI:            "file:///workspace/shapes.ts",
+		Range:          rng,
+		SelectionRange: rng,
+	}
+}
+
+func TestRecurseTypeHierarchy(t *testing.T) {
+	shape := typeItem("Shape", 0)
+	polygon := typeItem("Polygon", 10)
+	square := typeItem("Square", 20)
+	circle := typeItem("Circle", 30)
+
+	children := map[string][]protocol.TypeHierarchyItem{
+		"Shape":   {polygon, circle},
+		"Polygon": {square},
+	}
+	fetch := func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		return children[item.Name], nil
+	}
+
+	var result strings.Builder
+	recurseTypeHierarchy(&result, shape, "Subtype: ", fetch, 1, 5, map[string]bool{typeHierarchyKey(shape): true})
+
+	expected := "- Subtype: Circle (Class) - /workspace/shapes.ts:31\n" +
+		"- Subtype: Polygon (Class) - /workspace/shapes.ts:11\n" +
+		"  - Subtype: Square (Class) - /workspace/shapes.ts:21\n"
+	assert.Equal(t, expected, result.String())
+}
+
+func TestRecurseTypeHierarchy_DepthLimit(t *testing.T) {
+	fetch := func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		var line uint32
+		fmt.Sscanf(item.Name, "T%d", &line)
+		return []protocol.TypeHierarchyItem{typeItem(fmt.Sprintf("T%d", line+1), line+1)}, nil
+	}
+
+	root := typeItem("T0", 0)
+	var result strings.Builder
+	recurseTypeHierarchy(&result, root, "Supertype: ", fetch, 1, 2, map[string]bool{typeHierarchyKey(root): true})
+
+	expected := "- Supertype: T1 (Class) - /workspace/shapes.ts:2\n" +
+		"  - Supertype: T2 (Class) - /workspace/shapes.ts:3\n"
+	assert.Equal(t, expected, result.String())
+}
+
+func TestRecurseTypeHierarchy_Cycle(t *testing.T) {
+	a := typeItem("A", 0)
+	b := typeItem("B", 5)
+
+	fetch := func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		if item.Name == "A" {
+			return []protocol.TypeHierarchyItem{b}, nil
+		}
+		return []protocol.TypeHierarchyItem{a}, nil
+	}
+
+	var result strings.Builder
+	recurseTypeHierarchy(&result, a, "Supertype: ", fetch, 1, 10, map[string]bool{typeHierarchyKey(a): true})
+
+	expected := "- Supertype: B (Class) - /workspace/shapes.ts:6\n" +
+		"  - Supertype: A (Class) - /workspace/shapes.ts:1\n" +
+		"    (cycle)\n"
+	assert.Equal(t, expected, result.String())
+}
+
+func TestRecurseTypeHierarchy_Error(t *testing.T) {
+	fetch := func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		return nil, fmt.Errorf("not supported")
+	}
+
+	root := typeItem("A", 0)
+	var result strings.Builder
+	recurseTypeHierarchy(&result, root, "Subtype: ", fetch, 1, 3, map[string]bool{})
+
+	assert.Equal(t, "Error: not supported\n", result.String())
+}
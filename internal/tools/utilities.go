@@ -7,9 +7,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/protocol"
+	"github.com/vector67/mcp-language-server/internal/utilities"
 )
 
 func ExtractTextFromLocation(loc protocol.Location) (string, error) {
@@ -186,12 +188,21 @@ func doQuerySymbol(ctx context.Context, client *lsp.Client, symbolName string) (
 	return results, nil
 }
 
-func QuerySymbol(ctx context.Context, client *lsp.Client, symbolName string) (string, []protocol.WorkspaceSymbolResult, error) {
-	results, err := doQuerySymbol(ctx, client, symbolName)
+// ServerSymbol is a workspace/symbol result along with the server that
+// returned it, which requests about the symbol are sent to
+type ServerSymbol struct {
+	Client *lsp.Client
+	Symbol protocol.WorkspaceSymbolResult
+}
+
+// QuerySymbol looks symbolName up on every server, returning the name that
+// matched and the results of all servers in order
+func QuerySymbol(ctx context.Context, clients []*lsp.Client, symbolName string) (string, []ServerSymbol, error) {
+	results, err := querySymbols(ctx, clients, symbolName)
 
 	// clangd doesn't resolve "struct foo", only "foo"
 	if len(results) == 0 && strings.HasPrefix(symbolName, "struct ") {
-		results, err = doQuerySymbol(ctx, client, symbolName[7:])
+		results, err = querySymbols(ctx, clients, symbolName[7:])
 		if len(results) > 0 {
 			symbolName = symbolName[7:]
 		}
@@ -199,3 +210,78 @@ func QuerySymbol(ctx context.Context, client *lsp.Client, symbolName string) (st
 
 	return symbolName, results, err
 }
+
+// querySymbols sends a workspace/symbol query to every server at once and
+// merges the results. Servers that fail are skipped, unless all of them do.
+func querySymbols(ctx context.Context, clients []*lsp.Client, query string) ([]ServerSymbol, error) {
+	results := make([][]protocol.WorkspaceSymbolResult, len(clients))
+	errs := make([]error, len(clients))
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = doQuerySymbol(ctx, client, query)
+		}()
+	}
+	wg.Wait()
+
+	var merged []ServerSymbol
+	var firstErr error
+	failed := 0
+	for i, client := range clients {
+		if errs[i] != nil {
+			toolsLogger.Warn("Symbol query failed on %s: %v", client.Name(), errs[i])
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			failed++
+			continue
+		}
+		for _, symbol := range results[i] {
+			merged = append(merged, ServerSymbol{Client: client, Symbol: symbol})
+		}
+	}
+	if failed > 0 && failed == len(clients) {
+		return nil, firstErr
+	}
+	return merged, nil
+}
+
+// readLine returns a 0-indexed line of filePath without its line ending. It
+// returns false if the file has no such line.
+func readLine(filePath string, line int) (string, bool, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	if line < 0 || line >= len(lines) {
+		return "", false, nil
+	}
+	return strings.TrimSuffix(lines[line], "\r"), true, nil
+}
+
+// positionAt converts a 1-indexed line and column, with the column counted in
+// characters as an editor shows them, to a position in the given encoding
+func positionAt(filePath string, line, column int, encoding protocol.PositionEncodingKind) (protocol.Position, error) {
+	text, ok, err := readLine(filePath, line-1)
+	if err != nil {
+		return protocol.Position{}, err
+	}
+	position := protocol.Position{Line: uint32(line - 1), Character: uint32(column - 1)}
+	if ok {
+		position.Character = utilities.ColumnToCharacter(text, column, encoding)
+	}
+	return position, nil
+}
+
+// columnAt converts a position in the given encoding to a 1-indexed column
+// counted in characters
+func columnAt(filePath string, position protocol.Position, encoding protocol.PositionEncodingKind) int {
+	text, ok, err := readLine(filePath, int(position.Line))
+	if err != nil || !ok {
+		return int(position.Character) + 1
+	}
+	return utilities.CharacterToColumn(text, position.Character, encoding)
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -454,3 +455,31 @@ func TestFormatLinesWithRanges(t *testing.T) {
 		})
 	}
 }
+
+func TestPositionAt_MultiByte(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	writeTestFile(t, path, "package main\r\n\r\nvar s = \"🙂 café\"; var n = 1\r\n")
+
+	// Column 23 is the "n" after the emoji and the accented letter
+	tests := map[protocol.PositionEncodingKind]uint32{
+		protocol.UTF8:  26,
+		protocol.UTF16: 23,
+		protocol.UTF32: 22,
+	}
+	for encoding, character := range tests {
+		position, err := positionAt(path, 3, 23, encoding)
+		if err != nil {
+			t.Fatalf("positionAt failed: %v", err)
+		}
+		assert.Equal(t, protocol.Position{Line: 2, Character: character}, position, encoding)
+		assert.Equal(t, 23, columnAt(path, position, encoding), encoding)
+	}
+
+	// Lines past the end of the file are passed through for the server to reject
+	position, err := positionAt(path, 10, 5, protocol.UTF16)
+	assert.NoError(t, err)
+	assert.Equal(t, protocol.Position{Line: 9, Character: 4}, position)
+
+	_, err = positionAt(filepath.Join(t.TempDir(), "missing.go"), 1, 1, protocol.UTF16)
+	assert.Error(t, err)
+}
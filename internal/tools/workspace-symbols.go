@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// DefaultWorkspaceSymbolLimit is the number of results shown when no limit is given
+const DefaultWorkspaceSymbolLimit = 50
+
+// SearchWorkspaceSymbols lists the raw fuzzy matches of a workspace/symbol query.
+// Results can be filtered by symbol kind (e.g. "Function", "Struct") and by a
+// glob on the file path, and are capped at limit entries.
+func SearchWorkspaceSymbols(ctx context.Context, clients []*lsp.Client, query string, kinds []string, pathGlob string, limit int) (string, error) {
+	kindFilter, err := parseSymbolKinds(kinds)
+	if err != nil {
+		return "", err
+	}
+
+	if pathGlob != "" && !doublestar.ValidatePattern(pathGlob) {
+		return "", fmt.Errorf("invalid path glob: %s", pathGlob)
+	}
+
+	if limit <= 0 {
+		limit = DefaultWorkspaceSymbolLimit
+	}
+
+	results, err := querySymbols(ctx, clients, query)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	matched := 0
+	for _, result := range results {
+		client, symbol := result.Client, result.Symbol
+		var kind protocol.SymbolKind
+		var container string
+		switch v := symbol.(type) {
+		case *protocol.SymbolInformation:
+			kind = v.Kind
+			container = v.ContainerName
+		case *protocol.WorkspaceSymbol:
+			kind = v.Kind
+			container = v.ContainerName
+		}
+
+		if len(kindFilter) > 0 && !kindFilter[kind] {
+			continue
+		}
+
+		if pathGlob != "" && !matchesPathGlob(pathGlob, symbol.GetLocation().URI.Path()) {
+			continue
+		}
+
+		matched++
+		if len(lines) >= limit {
+			continue
+		}
+
+		// Servers may omit the range of a workspace symbol until it is resolved
+		loc := symbol.GetLocation()
+		if ws, ok := symbol.(*protocol.WorkspaceSymbol); ok {
+			if _, uriOnly := ws.Location.Value.(protocol.LocationUriOnly); uriOnly {
+				resolved, err := client.ResolveWorkspaceSymbol(ctx, *ws)
+				if err != nil {
+					toolsLogger.Warn("Failed to resolve workspace symbol %s: %v", ws.Name, err)
+				} else {
+					loc = resolved.GetLocation()
+				}
+			}
+		}
+
+		lines = append(lines, formatWorkspaceSymbol(symbol.GetName(), kind, container, loc))
+	}
+
+	if matched == 0 {
+		return fmt.Sprintf("No symbols found matching: %s", query), nil
+	}
+
+	var result strings.Builder
+	if matched > len(lines) {
+		result.WriteString(fmt.Sprintf("Found %d symbols matching %q (showing first %d):\n", matched, query, len(lines)))
+	} else {
+		result.WriteString(fmt.Sprintf("Found %d symbols matching %q:\n", matched, query))
+	}
+	result.WriteString(strings.Join(lines, "\n"))
+	result.WriteString("\n")
+
+	return result.String(), nil
+}
+
+// formatWorkspaceSymbol renders a single workspace symbol on one line
+func formatWorkspaceSymbol(name string, kind protocol.SymbolKind, container string, loc protocol.Location) string {
+	var line strings.Builder
+	line.WriteString(name)
+	if kindName, ok := protocol.TableKindMap[kind]; ok {
+		line.WriteString(fmt.Sprintf(" (%s)", kindName))
+	}
+	if container != "" {
+		line.WriteString(fmt.Sprintf(" in %s", container))
+	}
+	line.WriteString(" - ")
+	line.WriteString(strings.TrimPrefix(string(loc.URI), "file://"))
+	if loc.Range != (protocol.Range{}) {
+		line.WriteString(fmt.Sprintf(" L%d:C%d", loc.Range.Start.Line+1, loc.Range.Start.Character+1))
+	}
+	return line.String()
+}
+
+// parseSymbolKinds converts kind names (case-insensitive) into a set of SymbolKinds
+func parseSymbolKinds(kinds []string) (map[protocol.SymbolKind]bool, error) {
+	filter := make(map[protocol.SymbolKind]bool)
+	for _, name := range kinds {
+		found := false
+		for kind, kindName := range protocol.TableKindMap {
+			if strings.EqualFold(kindName, strings.TrimSpace(name)) {
+				filter[kind] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			valid := make([]string, 0, len(protocol.TableKindMap))
+			for _, kindName := range protocol.TableKindMap {
+				valid = append(valid, kindName)
+			}
+			sort.Strings(valid)
+			return nil, fmt.Errorf("unknown symbol kind %q. Valid kinds: %s", name, strings.Join(valid, ", "))
+		}
+	}
+	return filter, nil
+}
+
+// matchesPathGlob matches a file path against a glob. Relative globs match
+// anywhere in the path, so "internal/**/*.go" matches files under any internal directory.
+func matchesPathGlob(pattern string, path string) bool {
+	if !strings.HasPrefix(pattern, "/") && !strings.HasPrefix(pattern, "**") {
+		pattern = "**/" + pattern
+	}
+	match, err := doublestar.Match(pattern, path)
+	return err == nil && match
+}
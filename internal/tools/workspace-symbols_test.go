@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+func TestParseSymbolKinds(t *testing.T) {
+	filter, err := parseSymbolKinds([]string{"function", "Struct", " interface "})
+	assert.NoError(t, err)
+	assert.Equal(t, map[protocol.SymbolKind]bool{
+		protocol.Function:  true,
+		protocol.Struct:    true,
+		protocol.Interface: true,
+	}, filter)
+
+	filter, err = parseSymbolKinds(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, filter)
+
+	_, err = parseSymbolKinds([]string{"Widget"})
+	assert.ErrorContains(t, err, "unknown symbol kind \"Widget\"")
+}
+
+func TestMatchesPathGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"*.go", "/workspace/main.go", true},
+		{"*.go", "/workspace/main.ts", false},
+		{"internal/**/*.go", "/workspace/internal/tools/hover.go", true},
+		{"internal/**/*.go", "/workspace/cmd/main.go", false},
+		{"/workspace/cmd/*.go", "/workspace/cmd/main.go", true},
+		{"/workspace/cmd/*.go", "/other/cmd/main.go", false},
+		{"**/web/**", "/workspace/web/src/app.tsx", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern+" "+tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.expected, matchesPathGlob(tc.pattern, tc.path))
+		})
+	}
+}
+
+func TestFormatWorkspaceSymbol(t *testing.T) {
+	loc := protocol.Location{
+		URI: "file:///workspace/types.go",
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 5, Character: 5},
+			End:   protocol.Position{Line: 5, Character: 17},
+		},
+	}
+
+	assert.Equal(t,
+		"SharedStruct (Struct) in main - /workspace/types.go L6:C6",
+		formatWorkspaceSymbol("SharedStruct", protocol.Struct, "main", loc))
+
+	// Unresolved symbols have no range
+	assert.Equal(t,
+		"Process (Method) - /workspace/types.go",
+		formatWorkspaceSymbol("Process", protocol.Method, "", protocol.Location{URI: loc.URI}))
+}
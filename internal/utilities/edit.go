@@ -20,8 +20,9 @@ var (
 	osRename    = os.Rename
 )
 
-// ApplyTextEdits applies a sequence of text edits to a file specified by URI
-func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
+// ApplyTextEdits applies a sequence of text edits to a file specified by URI.
+// Edit positions are counted in code units of encoding.
+func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit, encoding protocol.PositionEncodingKind) error {
 	path := strings.TrimPrefix(string(uri), "file://")
 
 	// Read the file content
@@ -65,7 +66,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 
 	// Apply each edit
 	for _, edit := range sortedEdits {
-		newLines, err := ApplyTextEdit(lines, edit, lineEnding)
+		newLines, err := ApplyTextEdit(lines, edit, lineEnding, encoding)
 		if err != nil {
 			return fmt.Errorf("failed to apply edit: %w", err)
 		}
@@ -93,12 +94,11 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	return nil
 }
 
-// ApplyTextEdit applies a single text edit to a set of lines
-func ApplyTextEdit(lines []string, edit protocol.TextEdit, lineEnding string) ([]string, error) {
+// ApplyTextEdit applies a single text edit to a set of lines. Edit positions
+// are counted in code units of encoding.
+func ApplyTextEdit(lines []string, edit protocol.TextEdit, lineEnding string, encoding protocol.PositionEncodingKind) ([]string, error) {
 	startLine := int(edit.Range.Start.Line)
 	endLine := int(edit.Range.End.Line)
-	startChar := int(edit.Range.Start.Character)
-	endChar := int(edit.Range.End.Character)
 
 	// Validate positions
 	if startLine < 0 || startLine >= len(lines) {
@@ -116,16 +116,12 @@ func ApplyTextEdit(lines []string, edit protocol.TextEdit, lineEnding string) ([
 
 	// Get the prefix of the start line
 	startLineContent := lines[startLine]
-	if startChar < 0 || startChar > len(startLineContent) {
-		startChar = len(startLineContent)
-	}
+	startChar := CharacterToByteOffset(startLineContent, edit.Range.Start.Character, encoding)
 	prefix := startLineContent[:startChar]
 
 	// Get the suffix of the end line
 	endLineContent := lines[endLine]
-	if endChar < 0 || endChar > len(endLineContent) {
-		endChar = len(endLineContent)
-	}
+	endChar := CharacterToByteOffset(endLineContent, edit.Range.End.Character, encoding)
 	suffix := endLineContent[endChar:]
 
 	// Handle the edit
@@ -171,7 +167,7 @@ func ApplyTextEdit(lines []string, edit protocol.TextEdit, lineEnding string) ([
 }
 
 // ApplyDocumentChange applies a DocumentChange (create/rename/delete operations)
-func ApplyDocumentChange(change protocol.DocumentChange) error {
+func ApplyDocumentChange(change protocol.DocumentChange, encoding protocol.PositionEncodingKind) error {
 	if change.CreateFile != nil {
 		path := strings.TrimPrefix(string(change.CreateFile.URI), "file://")
 		if change.CreateFile.Options != nil {
@@ -225,17 +221,17 @@ func ApplyDocumentChange(change protocol.DocumentChange) error {
 				return fmt.Errorf("invalid edit type: %w", err)
 			}
 		}
-		return ApplyTextEdits(change.TextDocumentEdit.TextDocument.URI, textEdits)
+		return ApplyTextEdits(change.TextDocumentEdit.TextDocument.URI, textEdits, encoding)
 	}
 
 	return nil
 }
 
 // ApplyWorkspaceEdit applies the given WorkspaceEdit to the filesystem
-func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit) error {
+func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit, encoding protocol.PositionEncodingKind) error {
 	// Handle Changes field
 	for uri, textEdits := range edit.Changes {
-		if err := ApplyTextEdits(uri, textEdits); err != nil {
+		if err := ApplyTextEdits(uri, textEdits, encoding); err != nil {
 			return fmt.Errorf("failed to apply text edits: %w", err)
 		}
 	}
@@ -243,7 +239,7 @@ func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit) error {
 	// Handle DocumentChanges field
 	for _, change := range edit.DocumentChanges {
 		coreLogger.Warn("Document change: %v", spew.Sdump(change))
-		if err := ApplyDocumentChange(change); err != nil {
+		if err := ApplyDocumentChange(change, encoding); err != nil {
 			return fmt.Errorf("failed to apply document change: %w", err)
 		}
 	}
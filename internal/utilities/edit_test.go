@@ -445,7 +445,7 @@ func TestApplyTextEdit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := ApplyTextEdit(tt.lines, tt.edit, tt.lineEnding)
+			result, err := ApplyTextEdit(tt.lines, tt.edit, tt.lineEnding, protocol.UTF16)
 			if tt.expectErr {
 				if err == nil {
 					t.Errorf("Expected error but got none")
@@ -644,7 +644,7 @@ func TestApplyTextEdits(t *testing.T) {
 			cleanup := setupMockFileSystem(t, mfs)
 			defer cleanup()
 
-			err := ApplyTextEdits(tt.uri, tt.edits)
+			err := ApplyTextEdits(tt.uri, tt.edits, protocol.UTF16)
 			if tt.expectErr {
 				if err == nil {
 					t.Errorf("Expected error but got none")
@@ -896,7 +896,7 @@ func TestApplyDocumentChange(t *testing.T) {
 			cleanup := setupMockFileSystem(t, mfs)
 			defer cleanup()
 
-			err := ApplyDocumentChange(tt.change)
+			err := ApplyDocumentChange(tt.change, protocol.UTF16)
 			if tt.expectErr {
 				if err == nil {
 					t.Errorf("Expected error but got none")
@@ -1100,7 +1100,7 @@ func TestApplyWorkspaceEdit(t *testing.T) {
 			cleanup := setupMockFileSystem(t, mfs)
 			defer cleanup()
 
-			err := ApplyWorkspaceEdit(tt.edit)
+			err := ApplyWorkspaceEdit(tt.edit, protocol.UTF16)
 			if tt.expectErr {
 				if err == nil {
 					t.Errorf("Expected error but got none")
@@ -0,0 +1,86 @@
+package utilities
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// The LSP counts the character of a position in code units of the encoding
+// negotiated at initialization: bytes for UTF-8, 16-bit units for UTF-16 (the
+// default) and code points for UTF-32. Go strings are indexed by byte, and
+// users give columns in characters, so positions have to be converted in both
+// directions.
+
+// runeUnits returns the length of r in code units of encoding
+func runeUnits(r rune, encoding protocol.PositionEncodingKind) int {
+	switch encoding {
+	case protocol.UTF8:
+		return utf8.RuneLen(r)
+	case protocol.UTF32:
+		return 1
+	default:
+		return utf16.RuneLen(r)
+	}
+}
+
+// TextLength returns the length of text in code units of encoding
+func TextLength(text string, encoding protocol.PositionEncodingKind) uint32 {
+	if encoding == protocol.UTF8 {
+		return uint32(len(text))
+	}
+	var units int
+	for _, r := range text {
+		units += runeUnits(r, encoding)
+	}
+	return uint32(units)
+}
+
+// CharacterToByteOffset converts the character of a position on line to a byte
+// offset in line. Characters past the end of the line map to its length, and
+// a character inside a multi-unit rune maps to the start of that rune.
+func CharacterToByteOffset(line string, character uint32, encoding protocol.PositionEncodingKind) int {
+	var units uint32
+	for i, r := range line {
+		next := units + uint32(runeUnits(r, encoding))
+		if next > character {
+			return i
+		}
+		units = next
+	}
+	return len(line)
+}
+
+// ByteOffsetToCharacter converts a byte offset in line to the character of a
+// position
+func ByteOffsetToCharacter(line string, offset int, encoding protocol.PositionEncodingKind) uint32 {
+	offset = max(0, min(offset, len(line)))
+	return TextLength(line[:offset], encoding)
+}
+
+// ColumnToCharacter converts a 1-indexed column, counted in characters as an
+// editor shows them, to the character of a position on line. Columns past the
+// end of the line are kept past the end, so servers see the same overshoot.
+func ColumnToCharacter(line string, column int, encoding protocol.PositionEncodingKind) uint32 {
+	if column < 1 {
+		return 0
+	}
+	var units uint32
+	runes := 0
+	for _, r := range line {
+		if runes == column-1 {
+			return units
+		}
+		units += uint32(runeUnits(r, encoding))
+		runes++
+	}
+	return units + uint32(column-1-runes)
+}
+
+// CharacterToColumn converts the character of a position on line to a
+// 1-indexed column counted in characters
+func CharacterToColumn(line string, character uint32, encoding protocol.PositionEncodingKind) int {
+	offset := CharacterToByteOffset(line, character, encoding)
+	return utf8.RuneCountInString(line[:offset]) + 1
+}
@@ -0,0 +1,124 @@
+package utilities
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vector67/mcp-language-server/internal/protocol"
+)
+
+// Each fixture line has a marker character "x" after some multi-byte text:
+// é is 2 bytes and 1 UTF-16 unit, 世 is 3 bytes and 1 unit, and 🙂 is 4 bytes
+// and 2 units (a surrogate pair)
+var positionFixtures = []struct {
+	name  string
+	line  string
+	bytes int // byte offset of "x"
+	utf8  uint32
+	utf16 uint32
+	utf32 uint32
+}{
+	{name: "ascii", line: "abc x", bytes: 4, utf8: 4, utf16: 4, utf32: 4},
+	{name: "accent", line: "café x", bytes: 6, utf8: 6, utf16: 5, utf32: 5},
+	{name: "cjk", line: "世界 x", bytes: 7, utf8: 7, utf16: 3, utf32: 3},
+	{name: "emoji", line: "🙂🙂 x", bytes: 9, utf8: 9, utf16: 5, utf32: 3},
+	{name: "mixed", line: "é世🙂 x", bytes: 10, utf8: 10, utf16: 5, utf32: 4},
+}
+
+func TestPositionConversions(t *testing.T) {
+	for _, tt := range positionFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			characters := map[protocol.PositionEncodingKind]uint32{
+				protocol.UTF8:  tt.utf8,
+				protocol.UTF16: tt.utf16,
+				protocol.UTF32: tt.utf32,
+			}
+			for encoding, character := range characters {
+				if got := CharacterToByteOffset(tt.line, character, encoding); got != tt.bytes {
+					t.Errorf("CharacterToByteOffset(%d, %s) = %d, want %d", character, encoding, got, tt.bytes)
+				}
+				if got := ByteOffsetToCharacter(tt.line, tt.bytes, encoding); got != character {
+					t.Errorf("ByteOffsetToCharacter(%d, %s) = %d, want %d", tt.bytes, encoding, got, character)
+				}
+				// UTF-32 characters count runes, so they give the column
+				column := int(tt.utf32) + 1
+				if got := ColumnToCharacter(tt.line, column, encoding); got != character {
+					t.Errorf("ColumnToCharacter(%d, %s) = %d, want %d", column, encoding, got, character)
+				}
+				if got := CharacterToColumn(tt.line, character, encoding); got != column {
+					t.Errorf("CharacterToColumn(%d, %s) = %d, want %d", character, encoding, got, column)
+				}
+			}
+		})
+	}
+}
+
+func TestPositionConversions_OutOfRange(t *testing.T) {
+	line := "🙂 x"
+
+	// A character inside a surrogate pair maps to the start of the rune
+	if got := CharacterToByteOffset(line, 1, protocol.UTF16); got != 0 {
+		t.Errorf("expected offset 0 inside a surrogate pair, got %d", got)
+	}
+	// Characters past the end map to the end of the line
+	if got := CharacterToByteOffset(line, 100, protocol.UTF16); got != len(line) {
+		t.Errorf("expected offset %d past the end, got %d", len(line), got)
+	}
+	// Columns past the end keep their overshoot
+	if got := ColumnToCharacter(line, 6, protocol.UTF16); got != 6 {
+		t.Errorf("expected character 6 for column 6, got %d", got)
+	}
+	if got := ColumnToCharacter(line, 0, protocol.UTF16); got != 0 {
+		t.Errorf("expected character 0 for column 0, got %d", got)
+	}
+}
+
+func TestTextLength(t *testing.T) {
+	text := "é世🙂"
+	tests := map[protocol.PositionEncodingKind]uint32{
+		protocol.UTF8:  9,
+		protocol.UTF16: 4,
+		protocol.UTF32: 3,
+		// Servers that don't pick an encoding use UTF-16
+		"": 4,
+	}
+	for encoding, expected := range tests {
+		if got := TextLength(text, encoding); got != expected {
+			t.Errorf("TextLength(%q, %q) = %d, want %d", text, encoding, got, expected)
+		}
+	}
+}
+
+func TestApplyTextEdit_MultiByte(t *testing.T) {
+	lines := []string{"s := \"🙂 café\" // 世界"}
+	tests := []struct {
+		name     string
+		encoding protocol.PositionEncodingKind
+		start    uint32
+		end      uint32
+	}{
+		// Replace "café" in each encoding's units
+		{name: "utf-8", encoding: protocol.UTF8, start: 11, end: 16},
+		{name: "utf-16", encoding: protocol.UTF16, start: 9, end: 13},
+		{name: "utf-32", encoding: protocol.UTF32, start: 8, end: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edit := protocol.TextEdit{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 0, Character: tt.start},
+					End:   protocol.Position{Line: 0, Character: tt.end},
+				},
+				NewText: "thé",
+			}
+			result, err := ApplyTextEdit(lines, edit, "\n", tt.encoding)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected := []string{"s := \"🙂 thé\" // 世界"}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("ApplyTextEdit() result = %v, want %v", result, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchFile calls onChange whenever the file at path is created, written,
+// renamed or removed, until ctx is done. Bursts of changes within debounce of
+// each other result in a single call. The file's directory is watched rather
+// than the file itself, so the file may be created later or replaced by
+// editors that write a new file and rename it into place.
+func WatchFile(ctx context.Context, path string, debounce time.Duration, onChange func()) error {
+	path = filepath.Clean(path)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+
+	go func() {
+		defer func() {
+			if err := watcher.Close(); err != nil {
+				watcherLogger.Error("Error closing watcher: %v", err)
+			}
+		}()
+
+		var timer *time.Timer
+		for {
+			select {
+			case <-ctx.Done():
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != path || event.Op == fsnotify.Chmod {
+					continue
+				}
+				watcherLogger.Debug("Watched file changed: %s (%s)", path, event.Op)
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(debounce, onChange)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				watcherLogger.Error("Error watching %s: %v", path, err)
+			}
+		}
+	}()
+
+	return nil
+}
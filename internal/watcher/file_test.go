@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	changed := make(chan struct{}, 10)
+	err := WatchFile(ctx, path, 50*time.Millisecond, func() {
+		calls.Add(1)
+		changed <- struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("WatchFile failed: %v", err)
+	}
+
+	// Other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(dir, "other.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// Creating the file and writing it again right away is one change
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"gopls": {}}`), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change notification")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 change notification, got %d", n)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change notification when the file is removed")
+	}
+}
@@ -50,6 +50,11 @@ func (g *GitignoreMatcher) ShouldIgnore(path string, isDir bool) bool {
 		return false
 	}
 
-	// Use the go-gitignore Match function to check if the path should be ignored
+	// Use the go-gitignore Match function to check if the path should be ignored.
+	// Patterns that only match directories, such as "build/", need the
+	// trailing slash to match.
+	if isDir && g.gitignore.MatchesPath(relPath+"/") {
+		return true
+	}
 	return g.gitignore.MatchesPath(relPath)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/protocol"
 )
 
@@ -20,6 +21,12 @@ type LSPClient interface {
 
 	// DidChangeWatchedFiles sends watched file events to the server
 	DidChangeWatchedFiles(ctx context.Context, params protocol.DidChangeWatchedFilesParams) error
+
+	// RegisterFileWatchHandler sets the handler for file watchers the server registers
+	RegisterFileWatchHandler(handler lsp.FileWatchHandler)
+
+	// RegisterFileUnwatchHandler sets the handler for file watchers the server unregisters
+	RegisterFileUnwatchHandler(handler lsp.FileUnwatchHandler)
 }
 
 // WatcherConfig holds basic configuration for the watcher
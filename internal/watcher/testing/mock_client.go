@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/protocol"
 	"github.com/vector67/mcp-language-server/internal/watcher"
 )
@@ -111,6 +112,12 @@ func (m *MockLSPClient) DidChangeWatchedFiles(ctx context.Context, params protoc
 	return nil
 }
 
+// RegisterFileWatchHandler does nothing, as the mock has no server to register watchers
+func (m *MockLSPClient) RegisterFileWatchHandler(handler lsp.FileWatchHandler) {}
+
+// RegisterFileUnwatchHandler does nothing, as the mock has no server to unregister watchers
+func (m *MockLSPClient) RegisterFileUnwatchHandler(handler lsp.FileUnwatchHandler) {}
+
 // GetEvents returns a copy of all recorded events
 func (m *MockLSPClient) GetEvents() []FileEvent {
 	m.mu.Lock()
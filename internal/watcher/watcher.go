@@ -11,7 +11,6 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/vector67/mcp-language-server/internal/logging"
-	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/protocol"
 )
 
@@ -27,9 +26,11 @@ type WorkspaceWatcher struct {
 	debounceMap map[string]*time.Timer
 	debounceMu  sync.Mutex
 
-	// File watchers registered by the server
-	registrations  []protocol.FileSystemWatcher
-	registrationMu sync.RWMutex
+	// File watchers registered by the server, and the registration each
+	// came from so they can be unregistered
+	registrations   []protocol.FileSystemWatcher
+	registrationIDs map[string][]protocol.FileSystemWatcher
+	registrationMu  sync.RWMutex
 
 	// Gitignore matcher
 	gitignore *GitignoreMatcher
@@ -43,10 +44,11 @@ func NewWorkspaceWatcher(client LSPClient) *WorkspaceWatcher {
 // NewWorkspaceWatcherWithConfig creates a new workspace watcher with custom configuration
 func NewWorkspaceWatcherWithConfig(client LSPClient, config *WatcherConfig) *WorkspaceWatcher {
 	return &WorkspaceWatcher{
-		client:        client,
-		config:        config,
-		debounceMap:   make(map[string]*time.Timer),
-		registrations: []protocol.FileSystemWatcher{},
+		client:          client,
+		config:          config,
+		debounceMap:     make(map[string]*time.Timer),
+		registrations:   []protocol.FileSystemWatcher{},
+		registrationIDs: make(map[string][]protocol.FileSystemWatcher),
 	}
 }
 
@@ -57,6 +59,7 @@ func (w *WorkspaceWatcher) AddRegistrations(ctx context.Context, id string, watc
 
 	// Add new watchers
 	w.registrations = append(w.registrations, watchers...)
+	w.registrationIDs[id] = append(w.registrationIDs[id], watchers...)
 
 	// Log registration information
 	watcherLogger.Info("Added %d file watcher registrations (id: %s), total: %d",
@@ -153,6 +156,34 @@ func (w *WorkspaceWatcher) AddRegistrations(ctx context.Context, id string, watc
 	}()
 }
 
+// ResetRegistrations drops all file watchers registered by the server, for
+// when the server is restarted and will register its watchers again
+func (w *WorkspaceWatcher) ResetRegistrations() {
+	w.registrationMu.Lock()
+	defer w.registrationMu.Unlock()
+
+	watcherLogger.Info("Dropping %d file watcher registrations", len(w.registrations))
+	w.registrations = []protocol.FileSystemWatcher{}
+	w.registrationIDs = make(map[string][]protocol.FileSystemWatcher)
+}
+
+// RemoveRegistrations drops the file watchers added with the registration id
+func (w *WorkspaceWatcher) RemoveRegistrations(id string) {
+	w.registrationMu.Lock()
+	defer w.registrationMu.Unlock()
+
+	removed := len(w.registrationIDs[id])
+	delete(w.registrationIDs, id)
+
+	w.registrations = []protocol.FileSystemWatcher{}
+	for _, watchers := range w.registrationIDs {
+		w.registrations = append(w.registrations, watchers...)
+	}
+
+	watcherLogger.Info("Removed %d file watcher registrations (id: %s), total: %d",
+		removed, id, len(w.registrations))
+}
+
 // WatchWorkspace sets up file watching for a workspace
 func (w *WorkspaceWatcher) WatchWorkspace(ctx context.Context, workspacePath string) {
 	w.workspacePath = workspacePath
@@ -167,9 +198,10 @@ func (w *WorkspaceWatcher) WatchWorkspace(ctx context.Context, workspacePath str
 	}
 
 	// Register handler for file watcher registrations from the server
-	lsp.RegisterFileWatchHandler(func(id string, watchers []protocol.FileSystemWatcher) {
+	w.client.RegisterFileWatchHandler(func(id string, watchers []protocol.FileSystemWatcher) {
 		w.AddRegistrations(ctx, id, watchers)
 	})
+	w.client.RegisterFileUnwatchHandler(w.RemoveRegistrations)
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -1,6 +1,8 @@
 package watcher
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/vector67/mcp-language-server/internal/protocol"
@@ -63,3 +65,51 @@ func TestMatchesPattern_SimpleGlob(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoveRegistrations(t *testing.T) {
+	w := NewWorkspaceWatcherWithConfig(nil, DefaultWatcherConfig())
+	goFiles := protocol.FileSystemWatcher{GlobPattern: protocol.GlobPattern{Value: "**/*.go"}}
+	modFiles := protocol.FileSystemWatcher{GlobPattern: protocol.GlobPattern{Value: "**/go.mod"}}
+
+	w.AddRegistrations(t.Context(), "go", []protocol.FileSystemWatcher{goFiles})
+	w.AddRegistrations(t.Context(), "mod", []protocol.FileSystemWatcher{modFiles})
+	w.RemoveRegistrations("go")
+
+	if len(w.registrations) != 1 || w.registrations[0].GlobPattern.Value != "**/go.mod" {
+		t.Errorf("expected only the go.mod watcher to remain, got %+v", w.registrations)
+	}
+
+	// Unknown IDs are ignored
+	w.RemoveRegistrations("missing")
+	if len(w.registrations) != 1 {
+		t.Errorf("expected 1 watcher after removing an unknown id, got %d", len(w.registrations))
+	}
+}
+
+func TestGitignoreMatcher_DirectoryPatterns(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("build/\n*.log\n"), 0644); err != nil {
+		t.Fatalf("failed to write .gitignore: %v", err)
+	}
+	matcher, err := NewGitignoreMatcher(dir)
+	if err != nil {
+		t.Fatalf("NewGitignoreMatcher failed: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"build", true, true},
+		{"build/go.mod", false, true},
+		{"src", true, false},
+		{"debug.log", false, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := matcher.ShouldIgnore(filepath.Join(dir, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("ShouldIgnore(%s, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,20 +27,54 @@ import (
 // Create a logger for the core component
 var coreLogger = logging.NewLogger(logging.Core)
 
+// settingsDebounce is how long to wait after the settings file changes before
+// reloading it, so that editors' multi-step saves are read once
+const settingsDebounce = 200 * time.Millisecond
+
 type config struct {
 	workspaceDir string
 	lspCommand   string
 	openGlobs    StringArrayFlag
 	lspArgs      []string
+	lspSocket    string
+	lspTCP       string
+	settingsPath string
+	presetsPath  string
+	presetName   string
+	detectDepth  int
+	maxOpenFiles int
+	serverFlags  StringArrayFlag
+	// Language servers to route files to, the default server last
+	servers []lsp.ServerConfig
+
+	// MCP transport, "stdio" or "http", and for http the address to listen
+	// on, the bearer token clients must send, if any, and how long a session
+	// may go without requests before it is ended
+	transport          string
+	listen             string
+	authToken          string
+	sessionIdleTimeout time.Duration
 }
 
 type mcpServer struct {
-	config           config
-	lspClient        *lsp.Client
-	mcpServer        *server.MCPServer
-	ctx              context.Context
-	cancelFunc       context.CancelFunc
-	workspaceWatcher *watcher.WorkspaceWatcher
+	config     config
+	mcpServer  *server.MCPServer
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+
+	// Language servers in the order of config.servers, and the one that
+	// handles the files no other server does, if any
+	servers       []*languageServer
+	defaultServer *languageServer
+
+	// Settings from the settings file, for servers started later
+	settings   map[string]any
+	settingsMu sync.RWMutex
+
+	// Sessions of the MCP clients and the server they connect to, when
+	// serving over HTTP
+	sessions   *sessionStore
+	httpServer *http.Server
 }
 
 // StringArrayFlag is a custom flag type to handle an array of strings
@@ -56,7 +95,19 @@ func parseConfig() (*config, error) {
 	cfg := &config{}
 	flag.StringVar(&cfg.workspaceDir, "workspace", "", "Path to workspace directory")
 	flag.StringVar(&cfg.lspCommand, "lsp", "", "LSP command to run (args should be passed after --)")
+	flag.StringVar(&cfg.lspSocket, "lsp-socket", "", "Path of the Unix socket of an already running LSP server to connect to, instead of starting one")
+	flag.StringVar(&cfg.lspTCP, "lsp-tcp", "", "host:port of an already running LSP server to connect to, instead of starting one")
 	flag.Var(&cfg.openGlobs, "open", "Glob of files to open by default (can specify more than once)")
+	flag.StringVar(&cfg.settingsPath, "settings", "", "Path to a JSON file of language server settings (default: "+lsp.SettingsFileName+" in the workspace)")
+	flag.Var(&cfg.serverFlags, "server", "Language server for some languages or extensions, as <languages>=<command>, e.g. typescript,.tsx=\"typescript-language-server --stdio\", or the name of a preset (can specify more than once)")
+	flag.StringVar(&cfg.presetsPath, "presets", "", "Path to a YAML or JSON file of language server presets that replace and extend the built-in ones (default: mcp-language-server/"+lsp.PresetsFileName+" in the user config directory)")
+	flag.StringVar(&cfg.presetName, "preset", "", "Preset to use for the server given with --lsp, --lsp-socket or --lsp-tcp, instead of the one matching its command or detected from the workspace")
+	flag.StringVar(&cfg.transport, "transport", "stdio", "MCP transport: stdio, or http to serve several clients at once")
+	flag.StringVar(&cfg.listen, "listen", "127.0.0.1:8080", "Address to listen on with --transport http")
+	flag.StringVar(&cfg.authToken, "auth-token", os.Getenv("MCP_LANGUAGE_SERVER_TOKEN"), "Bearer token clients must send with --transport http (default: $MCP_LANGUAGE_SERVER_TOKEN)")
+	flag.DurationVar(&cfg.sessionIdleTimeout, "session-idle-timeout", defaultSessionIdleTimeout, "End --transport http sessions that send no requests for this long, closing their files (0 to keep them until the client ends them)")
+	flag.IntVar(&cfg.detectDepth, "detect-depth", lsp.DefaultDetectDepth, "Directory levels below the workspace root to search for projects when detecting language servers (0 for the root only)")
+	flag.IntVar(&cfg.maxOpenFiles, "max-open-files", lsp.DefaultMaxOpenFiles, "Maximum number of files to keep open in the language server, closing the least recently used beyond it (0 for no limit)")
 	flag.Parse()
 
 	// Get remaining args after -- as LSP arguments
@@ -82,33 +133,188 @@ func parseConfig() (*config, error) {
 		return nil, fmt.Errorf("workspace directory does not exist: %s", cfg.workspaceDir)
 	}
 
-	// Auto-detect LSP server if not specified
-	if cfg.lspCommand == "" {
-		detected, err := lsp.DetectServer(cfg.workspaceDir)
+	switch cfg.transport {
+	case "stdio":
+	case "http":
+		host, _, err := net.SplitHostPort(cfg.listen)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --listen address %q: %v", cfg.listen, err)
+		}
+		if ip := net.ParseIP(host); cfg.authToken == "" && host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			coreLogger.Warn("Listening on %s without --auth-token lets anyone who can reach it use the language server and edit files", cfg.listen)
+		}
+	default:
+		return nil, fmt.Errorf("invalid --transport %q: expected stdio or http", cfg.transport)
+	}
+
+	if cfg.detectDepth < 0 {
+		return nil, fmt.Errorf("--detect-depth must not be negative: %d", cfg.detectDepth)
+	}
+
+	if cfg.maxOpenFiles < 0 {
+		return nil, fmt.Errorf("--max-open-files must not be negative: %d", cfg.maxOpenFiles)
+	}
+
+	// An explicit settings file must exist; the default one is optional
+	if cfg.settingsPath != "" {
+		settingsPath, err := filepath.Abs(cfg.settingsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for settings: %v", err)
+		}
+		if _, err := os.Stat(settingsPath); err != nil {
+			return nil, fmt.Errorf("settings file not found: %v", err)
+		}
+		cfg.settingsPath = settingsPath
+	} else {
+		cfg.settingsPath = filepath.Join(cfg.workspaceDir, lsp.SettingsFileName)
+	}
+
+	// Presets from the presets file replace and extend the built-in ones
+	presets := lsp.BuiltinPresets()
+	if cfg.presetsPath == "" {
+		cfg.presetsPath = lsp.DefaultPresetsPath()
+	}
+	if cfg.presetsPath != "" {
+		presets, err = lsp.LoadPresets(cfg.presetsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load presets: %v", err)
+		}
+		coreLogger.Info("Loaded presets from %s", cfg.presetsPath)
+	}
+
+	var defaultPreset *lsp.Preset
+	if cfg.presetName != "" {
+		if defaultPreset = presets.Find(cfg.presetName); defaultPreset == nil {
+			return nil, fmt.Errorf("unknown preset %q", cfg.presetName)
+		}
+	}
+
+	// A running server to connect to replaces the default server
+	var connectTo *lsp.ServerConfig
+	switch {
+	case cfg.lspSocket != "" && cfg.lspTCP != "":
+		return nil, fmt.Errorf("--lsp-socket and --lsp-tcp cannot be used together")
+	case (cfg.lspSocket != "" || cfg.lspTCP != "") && cfg.lspCommand != "":
+		return nil, fmt.Errorf("--lsp cannot be used with --lsp-socket or --lsp-tcp")
+	case cfg.lspSocket != "":
+		socketPath, err := filepath.Abs(cfg.lspSocket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for socket: %v", err)
+		}
+		connectTo = &lsp.ServerConfig{Network: "unix", Address: socketPath}
+	case cfg.lspTCP != "":
+		if _, _, err := net.SplitHostPort(cfg.lspTCP); err != nil {
+			return nil, fmt.Errorf("invalid --lsp-tcp address %q: %v", cfg.lspTCP, err)
+		}
+		connectTo = &lsp.ServerConfig{Network: "tcp", Address: cfg.lspTCP}
+	}
+
+	for _, value := range cfg.serverFlags {
+		// A preset name gives both the command and the languages
+		if preset := presets.Find(value); preset != nil {
+			if len(preset.Languages) == 0 {
+				return nil, fmt.Errorf("preset %s has no languages to route to it", preset.Name)
+			}
+			cfg.servers = append(cfg.servers, preset.ServerConfig())
+			continue
+		}
+		serverConfig, err := lsp.ParseServerConfig(value)
 		if err != nil {
 			return nil, err
 		}
-		cfg.lspCommand = detected.Command
-		if len(cfg.lspArgs) == 0 && len(detected.Args) > 0 {
-			cfg.lspArgs = detected.Args
+		serverConfig.Preset = presets.ForCommand(serverConfig.Command)
+		cfg.servers = append(cfg.servers, serverConfig)
+	}
+
+	// Auto-detect LSP servers if not specified
+	if cfg.lspCommand == "" && connectTo == nil && len(cfg.servers) == 0 && defaultPreset == nil {
+		detected, err := detectServers(cfg.workspaceDir, cfg.detectDepth, presets)
+		if err != nil {
+			return nil, err
+		}
+		if len(detected) == 1 && len(cfg.lspArgs) > 0 {
+			detected[0].Args = cfg.lspArgs
+		}
+		cfg.servers = detected
+	} else if cfg.lspCommand == "" && connectTo == nil && defaultPreset != nil {
+		cfg.lspCommand = defaultPreset.Command
+		if len(cfg.lspArgs) == 0 && len(defaultPreset.Args) > 0 {
+			cfg.lspArgs = defaultPreset.Args
 		}
-		coreLogger.Info("Auto-detected LSP server: %s %v", cfg.lspCommand, cfg.lspArgs)
 	}
 
-	if _, err := exec.LookPath(cfg.lspCommand); err != nil {
-		return nil, fmt.Errorf("LSP command %q not found in PATH. Install it or use --lsp to specify a different server", cfg.lspCommand)
+	if connectTo != nil {
+		connectTo.Preset = defaultPreset
+		cfg.servers = append(cfg.servers, *connectTo)
+	} else if cfg.lspCommand != "" {
+		if defaultPreset == nil {
+			defaultPreset = presets.ForCommand(cfg.lspCommand)
+		}
+		cfg.servers = append(cfg.servers, lsp.ServerConfig{Command: cfg.lspCommand, Args: cfg.lspArgs, Preset: defaultPreset})
+	}
+
+	for _, serverConfig := range cfg.servers {
+		if serverConfig.Command == "" {
+			continue
+		}
+		if _, err := exec.LookPath(serverConfig.Command); err != nil {
+			return nil, fmt.Errorf("LSP command %q not found in PATH. Install it or use --lsp to specify a different server", serverConfig.Command)
+		}
 	}
 
 	return cfg, nil
 }
 
+// detectServers finds the projects in the workspace, skipping gitignored
+// directories, and returns a server for each kind of project found
+func detectServers(workspaceDir string, depth int, presets lsp.Presets) ([]lsp.ServerConfig, error) {
+	gitignore, err := watcher.NewGitignoreMatcher(workspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitignore: %v", err)
+	}
+	roots, err := presets.DetectRoots(workspaceDir, depth, gitignore.ShouldIgnore)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, root := range roots {
+		rel, err := filepath.Rel(workspaceDir, root.Dir)
+		if err != nil {
+			rel = root.Dir
+		}
+		languages := "unknown"
+		if len(root.Preset.Languages) > 0 {
+			languages = strings.Join(root.Preset.Languages, ", ")
+		}
+		coreLogger.Info("Detected project root %s (%s): %s", rel, languages, root.Preset.Name)
+	}
+
+	servers := lsp.ServerConfigsForRoots(roots)
+	for _, serverConfig := range servers {
+		coreLogger.Info("Auto-detected LSP server: %s for %s", serverConfig.Preset.Name, strings.Join(serverConfig.Roots, ", "))
+	}
+	return servers, nil
+}
+
 func newServer(config *config) (*mcpServer, error) {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &mcpServer{
+	s := &mcpServer{
 		config:     *config,
 		ctx:        ctx,
 		cancelFunc: cancel,
-	}, nil
+	}
+	// Created up front so that cleanup can shut it down at any time
+	if config.transport == "http" {
+		s.httpServer = &http.Server{Addr: config.listen}
+	}
+	for _, serverConfig := range config.servers {
+		ls := &languageServer{config: serverConfig}
+		s.servers = append(s.servers, ls)
+		if len(serverConfig.Languages) == 0 {
+			s.defaultServer = ls
+		}
+	}
+	return s, nil
 }
 
 func (s *mcpServer) initializeLSP() error {
@@ -116,26 +322,57 @@ func (s *mcpServer) initializeLSP() error {
 		return fmt.Errorf("failed to change to workspace directory: %v", err)
 	}
 
-	client, err := lsp.NewClient(s.config.lspCommand, s.config.lspArgs...)
-	if err != nil {
-		return fmt.Errorf("failed to create LSP client: %v", err)
+	if err := s.loadSettings(); err != nil {
+		return err
 	}
-	s.lspClient = client
-	s.workspaceWatcher = watcher.NewWorkspaceWatcher(client)
 
-	initResult, err := client.InitializeLSPClient(s.ctx, s.config.workspaceDir)
+	// Push the settings to the servers again whenever the file changes
+	err := watcher.WatchFile(s.ctx, s.config.settingsPath, settingsDebounce, func() {
+		if err := s.loadSettings(); err != nil {
+			coreLogger.Error("Failed to reload settings: %v", err)
+		}
+	})
 	if err != nil {
-		return fmt.Errorf("initialize failed: %v", err)
+		coreLogger.Error("Failed to watch settings file: %v", err)
 	}
 
-	coreLogger.Debug("Server capabilities: %+v", initResult.Capabilities)
+	// The default server starts right away, the others when a tool needs them
+	if s.defaultServer != nil {
+		if _, err := s.startServer(s.defaultServer); err != nil {
+			return err
+		}
+	}
 
 	if len(s.config.openGlobs) > 0 {
 		s.openInitialFiles()
 	}
 
-	go s.workspaceWatcher.WatchWorkspace(s.ctx, s.config.workspaceDir)
-	return client.WaitForServerReady(s.ctx)
+	return nil
+}
+
+// loadSettings reads the settings file and hands the settings to the running
+// servers' clients, which send them to the servers once they are initialized.
+// A missing file means no settings.
+func (s *mcpServer) loadSettings() error {
+	settings, err := lsp.LoadSettings(s.config.settingsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		settings = nil
+	} else if err != nil {
+		return err
+	} else {
+		coreLogger.Info("Loaded settings from %s", s.config.settingsPath)
+	}
+
+	s.settingsMu.Lock()
+	s.settings = settings
+	s.settingsMu.Unlock()
+
+	for _, client := range s.runningClients() {
+		if err := client.SetSettings(s.ctx, settings); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *mcpServer) openInitialFiles() {
@@ -153,7 +390,12 @@ func (s *mcpServer) openInitialFiles() {
 				}
 
 				if match {
-					if err := s.lspClient.OpenFile(s.ctx, path); err != nil {
+					client, err := s.clientFor(path)
+					if err != nil {
+						coreLogger.Warn("Not opening %s: %v", path, err)
+						break
+					}
+					if err := client.OpenStartupFile(s.ctx, path); err != nil {
 						coreLogger.Error("Failed to open file %s: %v", path, err)
 					}
 					break
@@ -173,21 +415,52 @@ func (s *mcpServer) start() error {
 		return err
 	}
 
-	s.mcpServer = server.NewMCPServer(
-		"MCP Language Server",
-		"v0.0.2",
+	options := []server.ServerOption{
 		server.WithLogging(),
 		server.WithRecovery(),
+	}
+	if s.config.transport == "http" {
+		s.sessions = newSessionStore(s.closeSessionFiles)
+		go s.sessions.expireIdle(s.ctx, s.config.sessionIdleTimeout)
+		options = append(options, server.WithToolHandlerMiddleware(s.trackSessions))
+	}
+	options = append(options,
+		server.WithToolHandlerMiddleware(s.routeToServers),
+		server.WithToolHandlerMiddleware(s.forwardProgress),
+		server.WithToolHandlerMiddleware(s.waitForIndexing),
 	)
+	s.mcpServer = server.NewMCPServer("MCP Language Server", "v0.0.2", options...)
 
 	err := s.registerTools()
 	if err != nil {
 		return fmt.Errorf("tool registration failed: %v", err)
 	}
 
+	if s.config.transport == "http" {
+		return s.serveHTTP()
+	}
 	return server.ServeStdio(s.mcpServer)
 }
 
+// serveHTTP serves MCP over streamable HTTP at /mcp until the server is shut
+// down, so that several clients can share the language servers
+func (s *mcpServer) serveHTTP() error {
+	var handler http.Handler = server.NewStreamableHTTPServer(s.mcpServer,
+		server.WithSessionIdManager(s.sessions))
+	if s.config.authToken != "" {
+		handler = requireToken(s.config.authToken, handler)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+	s.httpServer.Handler = mux
+
+	coreLogger.Info("Serving MCP over HTTP at http://%s/mcp", s.config.listen)
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
 func main() {
 	coreLogger.Info("MCP Language Server starting")
 
@@ -209,8 +482,13 @@ func main() {
 	parentDeath := make(chan struct{})
 
 	// Monitor parent process termination
-	// Claude desktop does not properly kill child processes for MCP servers
+	// Claude desktop does not properly kill child processes for MCP servers.
+	// Over HTTP the server outlives the process that started it, so it
+	// keeps running until it is signalled.
 	go func() {
+		if config.transport == "http" {
+			return
+		}
 		ppid := os.Getppid()
 		coreLogger.Debug("Monitoring parent process: %d", ppid)
 
@@ -258,45 +536,22 @@ func main() {
 func cleanup(s *mcpServer, done chan struct{}) {
 	coreLogger.Info("Cleanup initiated for PID: %d", os.Getpid())
 
+	// Stop the supervisor first so the server exiting is not seen as a crash
+	s.cancelFunc()
+
 	// Create a context with timeout for shutdown operations
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if s.lspClient != nil {
-		coreLogger.Info("Closing open files")
-		s.lspClient.CloseAllFiles(ctx)
-
-		// Create a shorter timeout context for the shutdown request
-		shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 500*time.Millisecond)
-		defer shutdownCancel()
-
-		// Run shutdown in a goroutine with timeout to avoid blocking if LSP doesn't respond
-		shutdownDone := make(chan struct{})
-		go func() {
-			coreLogger.Info("Sending shutdown request")
-			if err := s.lspClient.Shutdown(shutdownCtx); err != nil {
-				coreLogger.Error("Shutdown request failed: %v", err)
-			}
-			close(shutdownDone)
-		}()
-
-		// Wait for shutdown with timeout
-		select {
-		case <-shutdownDone:
-			coreLogger.Info("Shutdown request completed")
-		case <-time.After(1 * time.Second):
-			coreLogger.Warn("Shutdown request timed out, proceeding with exit")
-		}
-
-		coreLogger.Info("Sending exit notification")
-		if err := s.lspClient.Exit(ctx); err != nil {
-			coreLogger.Error("Exit notification failed: %v", err)
+	// Stop taking requests before the language servers go away
+	if s.httpServer != nil {
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			coreLogger.Error("Failed to shut down HTTP server: %v", err)
 		}
+	}
 
-		coreLogger.Info("Closing LSP client")
-		if err := s.lspClient.Close(); err != nil {
-			coreLogger.Error("Failed to close LSP client: %v", err)
-		}
+	for _, client := range s.runningClients() {
+		shutdownClient(ctx, client)
 	}
 
 	// Send signal to the done channel
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vector67/mcp-language-server/internal/lsp"
+	"github.com/vector67/mcp-language-server/internal/watcher"
+)
+
+// languageServer is one of the language servers tools are routed to. It is
+// started the first time a tool needs it.
+type languageServer struct {
+	config lsp.ServerConfig
+
+	mu         sync.Mutex
+	client     *lsp.Client
+	watcher    *watcher.WorkspaceWatcher
+	supervisor *lsp.Supervisor
+}
+
+// running returns the server's client, or nil if it hasn't been started
+func (ls *languageServer) running() *lsp.Client {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.client
+}
+
+// startServer starts and initializes ls unless it is already running, and
+// returns its client. If starting fails, the next call tries again.
+func (s *mcpServer) startServer(ls *languageServer) (*lsp.Client, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.client != nil {
+		return ls.client, nil
+	}
+
+	coreLogger.Info("Starting language server: %s", ls.config)
+	client, err := lsp.NewClientWithTransport(ls.config.Transport())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LSP client for %s: %v", ls.config, err)
+	}
+	client.SetPreset(ls.config.Preset)
+	client.SetRoots(ls.config.Roots)
+	client.SetMaxOpenFiles(s.ctx, s.config.maxOpenFiles)
+	if err := client.SetSettings(s.ctx, s.currentSettings()); err != nil {
+		return nil, err
+	}
+	workspaceWatcher := watcher.NewWorkspaceWatcher(client)
+
+	initResult, err := client.InitializeLSPClient(s.ctx, s.config.workspaceDir)
+	if err != nil {
+		if err := client.Close(); err != nil {
+			coreLogger.Error("Failed to close LSP client: %v", err)
+		}
+		return nil, fmt.Errorf("initialize failed for %s: %v", ls.config, err)
+	}
+
+	coreLogger.Debug("Server capabilities of %s: %+v", ls.config, initResult.Capabilities)
+
+	go workspaceWatcher.WatchWorkspace(s.ctx, s.config.workspaceDir)
+
+	// Restart the server if it crashes. The new server registers its file
+	// watchers again, so the old registrations are dropped first.
+	supervisor := lsp.NewSupervisor(client, s.config.workspaceDir, workspaceWatcher.ResetRegistrations)
+	go supervisor.Run(s.ctx)
+
+	if err := client.WaitForServerReady(s.ctx); err != nil {
+		return nil, err
+	}
+
+	ls.client, ls.watcher, ls.supervisor = client, workspaceWatcher, supervisor
+	return client, nil
+}
+
+// serverFor returns the server for the file at path: the one configured for
+// its language or extension, or else the default server
+func (s *mcpServer) serverFor(path string) (*languageServer, error) {
+	for _, ls := range s.servers {
+		if ls.config.Handles(path) {
+			return ls, nil
+		}
+	}
+	if s.defaultServer != nil {
+		return s.defaultServer, nil
+	}
+	return nil, fmt.Errorf("no language server is configured for %s", path)
+}
+
+// clientFor returns the client of the server for the file at path, starting
+// the server if needed
+func (s *mcpServer) clientFor(path string) (*lsp.Client, error) {
+	ls, err := s.serverFor(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.startServer(ls)
+}
+
+// allClients starts every server that isn't running and returns their
+// clients. Servers that fail to start are left out, unless all of them do.
+func (s *mcpServer) allClients() ([]*lsp.Client, error) {
+	clients := make([]*lsp.Client, len(s.servers))
+	errs := make([]error, len(s.servers))
+	var wg sync.WaitGroup
+	for i, ls := range s.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients[i], errs[i] = s.startServer(ls)
+		}()
+	}
+	wg.Wait()
+
+	started := make([]*lsp.Client, 0, len(clients))
+	for i, client := range clients {
+		if errs[i] != nil {
+			coreLogger.Error("Failed to start %s: %v", s.servers[i].config, errs[i])
+			continue
+		}
+		started = append(started, client)
+	}
+	if len(started) == 0 && len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return started, nil
+}
+
+// runningClients returns the clients of the servers that have been started
+func (s *mcpServer) runningClients() []*lsp.Client {
+	var clients []*lsp.Client
+	for _, ls := range s.servers {
+		if client := ls.running(); client != nil {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// currentSettings returns the settings last loaded from the settings file
+func (s *mcpServer) currentSettings() map[string]any {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.settings
+}
+
+// shutdownClient closes the files a server has open and asks it to exit,
+// killing it if it doesn't. Servers that were already running when we
+// connected to them are left running, since editors may share them.
+func shutdownClient(ctx context.Context, client *lsp.Client) {
+	stats := client.OpenFileStats()
+	coreLogger.Info("Closing %d open files of %s (%d evicted during the session, limit exceeded %d times)",
+		stats.Open, client.Name(), stats.Evictions, stats.Overflows)
+	client.CloseAllFiles(ctx)
+
+	if !client.OwnsServer() {
+		coreLogger.Info("Disconnecting from %s", client.Name())
+		if err := client.Close(); err != nil {
+			coreLogger.Error("Failed to close LSP client: %v", err)
+		}
+		return
+	}
+
+	// Create a shorter timeout context for the shutdown request
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer shutdownCancel()
+
+	// Run shutdown in a goroutine with timeout to avoid blocking if LSP doesn't respond
+	shutdownDone := make(chan struct{})
+	go func() {
+		coreLogger.Info("Sending shutdown request to %s", client.Name())
+		if err := client.Shutdown(shutdownCtx); err != nil {
+			coreLogger.Error("Shutdown request failed: %v", err)
+		}
+		close(shutdownDone)
+	}()
+
+	// Wait for shutdown with timeout
+	select {
+	case <-shutdownDone:
+		coreLogger.Info("Shutdown request completed")
+	case <-time.After(1 * time.Second):
+		coreLogger.Warn("Shutdown request timed out, proceeding with exit")
+	}
+
+	coreLogger.Info("Sending exit notification")
+	if err := client.Exit(ctx); err != nil {
+		coreLogger.Error("Exit notification failed: %v", err)
+	}
+
+	coreLogger.Info("Closing LSP client")
+	if err := client.Close(); err != nil {
+		coreLogger.Error("Failed to close LSP client: %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultSessionIdleTimeout is how long a session may go without requests
+// before it is ended, for clients that go away without ending their session
+const defaultSessionIdleTimeout = 30 * time.Minute
+
+// session is the state of one MCP client connected over HTTP
+type session struct {
+	started  time.Time
+	lastSeen time.Time
+	// Files the session's tool calls were about
+	files map[string]struct{}
+}
+
+// sessionStore tracks the sessions of MCP clients connected over HTTP, which
+// share the language servers. It issues the session IDs, so that requests
+// with an ID it didn't issue, or for a session that has ended, are rejected.
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+
+	// Called with the files only an ending session was using
+	onEnd func(id string, files []string)
+}
+
+func newSessionStore(onEnd func(id string, files []string)) *sessionStore {
+	return &sessionStore{
+		sessions: make(map[string]*session),
+		onEnd:    onEnd,
+	}
+}
+
+// Generate starts a session and returns its ID
+func (st *sessionStore) Generate() string {
+	id := "mcp-session-" + rand.Text()
+
+	st.mu.Lock()
+	now := time.Now()
+	st.sessions[id] = &session{started: now, lastSeen: now, files: make(map[string]struct{})}
+	count := len(st.sessions)
+	st.mu.Unlock()
+
+	coreLogger.Info("Session %s started (%d active)", id, count)
+	return id
+}
+
+// Validate reports whether the session has ended. IDs this store never issued
+// are treated as ended, which tells the client to start a new session. It is
+// called for every request of a session, so it also marks the session active.
+func (st *sessionStore) Validate(id string) (isTerminated bool, err error) {
+	if id == "" {
+		return false, fmt.Errorf("missing session ID")
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.sessions[id]
+	if ok {
+		s.lastSeen = time.Now()
+	}
+	return !ok, nil
+}
+
+// Terminate ends the session, closing the files no other session is using
+func (st *sessionStore) Terminate(id string) (isNotAllowed bool, err error) {
+	st.mu.Lock()
+	ended, ok := st.sessions[id]
+	if !ok {
+		st.mu.Unlock()
+		return false, nil
+	}
+	delete(st.sessions, id)
+
+	var files []string
+	for file := range ended.files {
+		if !st.usedLocked(file) {
+			files = append(files, file)
+		}
+	}
+	count := len(st.sessions)
+	st.mu.Unlock()
+
+	coreLogger.Info("Session %s ended after %s (%d active)", id, time.Since(ended.started).Round(time.Second), count)
+	if st.onEnd != nil {
+		st.onEnd(id, files)
+	}
+	return false, nil
+}
+
+// expireIdle ends the sessions that have sent no requests for timeout, until
+// ctx is done. A timeout of 0 keeps sessions until their clients end them.
+func (st *sessionStore) expireIdle(ctx context.Context, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(min(timeout/2, time.Minute))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, id := range st.idle(now, timeout) {
+				coreLogger.Info("Session %s has been idle for over %s", id, timeout)
+				_, _ = st.Terminate(id)
+			}
+		}
+	}
+}
+
+// idle returns the sessions that have sent no requests for timeout before now
+func (st *sessionStore) idle(now time.Time, timeout time.Duration) []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var ids []string
+	for id, s := range st.sessions {
+		if now.Sub(s.lastSeen) > timeout {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// usedLocked reports whether any session is using file. mu must be held.
+func (st *sessionStore) usedLocked(file string) bool {
+	for _, s := range st.sessions {
+		if _, ok := s.files[file]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// useFile records that the session used file
+func (st *sessionStore) useFile(id, file string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if s, ok := st.sessions[id]; ok {
+		s.files[file] = struct{}{}
+	}
+}
+
+// trackSessions is a tool middleware that records the files each HTTP
+// session's tool calls are about, so they can be closed on the language
+// servers when the session ends
+func (s *mcpServer) trackSessions(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if filePath := request.GetString("filePath", ""); filePath != "" {
+			if clientSession := server.ClientSessionFromContext(ctx); clientSession != nil {
+				s.sessions.useFile(clientSession.SessionID(), filePath)
+			}
+		}
+		return next(ctx, request)
+	}
+}
+
+// closeSessionFiles closes the files of an ended session on the servers that
+// have them open, leaving open those opened at startup with --open or a
+// preset's preopen globs
+func (s *mcpServer) closeSessionFiles(id string, files []string) {
+	for _, file := range files {
+		ls, err := s.serverFor(file)
+		if err != nil {
+			continue
+		}
+		client := ls.running()
+		if client == nil || client.StartupFile(file) {
+			continue
+		}
+		if err := client.CloseFile(s.ctx, file); err != nil {
+			coreLogger.Error("Failed to close %s after session %s ended: %v", file, id, err)
+		}
+	}
+}
+
+// requireToken wraps handler so that requests without the bearer token are
+// rejected
+func requireToken(token string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-language-server"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRequireToken(t *testing.T) {
+	handler := requireToken("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		expected      int
+	}{
+		{"Missing", "", http.StatusUnauthorized},
+		{"WrongToken", "Bearer wrong", http.StatusUnauthorized},
+		{"WrongScheme", "Basic secret", http.StatusUnauthorized},
+		{"PrefixOfToken", "Bearer secre", http.StatusUnauthorized},
+		{"CorrectToken", "Bearer secret", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, recorder.Code)
+			}
+			if tt.expected == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate header on a rejected request")
+			}
+		})
+	}
+}
+
+// endedSession records a call of a session store's onEnd
+type endedSession struct {
+	id    string
+	files []string
+}
+
+// newTestSessionStore returns a session store that sends the sessions it ends
+// on the returned channel
+func newTestSessionStore() (*sessionStore, <-chan endedSession) {
+	ended := make(chan endedSession, 10)
+	store := newSessionStore(func(id string, files []string) {
+		sort.Strings(files)
+		ended <- endedSession{id, files}
+	})
+	return store, ended
+}
+
+// expectEnded checks that the next session to end is expected
+func expectEnded(t *testing.T, ended <-chan endedSession, expected endedSession) {
+	t.Helper()
+	select {
+	case got := <-ended:
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v to end, got %v", expected, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected %v to end", expected)
+	}
+}
+
+func TestSessionStore_Lifecycle(t *testing.T) {
+	store, ended := newTestSessionStore()
+
+	first, second := store.Generate(), store.Generate()
+	if first == second {
+		t.Fatalf("expected distinct session IDs, got %s twice", first)
+	}
+
+	validate := []struct {
+		name           string
+		id             string
+		wantTerminated bool
+		wantErr        bool
+	}{
+		{"Active", first, false, false},
+		{"Unknown", "mcp-session-unknown", true, false},
+		{"Missing", "", false, true},
+	}
+	for _, tt := range validate {
+		t.Run("Validate"+tt.name, func(t *testing.T) {
+			terminated, err := store.Validate(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate(%q) error = %v, want an error: %v", tt.id, err, tt.wantErr)
+			}
+			if terminated != tt.wantTerminated {
+				t.Errorf("Validate(%q) = %v, want %v", tt.id, terminated, tt.wantTerminated)
+			}
+		})
+	}
+
+	store.useFile(first, "/workspace/only_first.go")
+	store.useFile(first, "/workspace/shared.go")
+	store.useFile(second, "/workspace/shared.go")
+
+	// Only the files no other session is using are closed
+	if _, err := store.Terminate(first); err != nil {
+		t.Fatalf("Terminate failed: %v", err)
+	}
+	expectEnded(t, ended, endedSession{first, []string{"/workspace/only_first.go"}})
+	if terminated, _ := store.Validate(first); !terminated {
+		t.Error("expected an ended session to be reported as terminated")
+	}
+
+	// Ending a session again, or one that never existed, does nothing
+	for _, id := range []string{first, "mcp-session-unknown"} {
+		if _, err := store.Terminate(id); err != nil {
+			t.Fatalf("Terminate(%s) failed: %v", id, err)
+		}
+	}
+	select {
+	case got := <-ended:
+		t.Errorf("expected no session to end, got %v", got)
+	default:
+	}
+
+	if _, err := store.Terminate(second); err != nil {
+		t.Fatalf("Terminate failed: %v", err)
+	}
+	expectEnded(t, ended, endedSession{second, []string{"/workspace/shared.go"}})
+}
+
+func TestSessionStore_ExpiresIdleSessions(t *testing.T) {
+	store, ended := newTestSessionStore()
+	idle, active := store.Generate(), store.Generate()
+	store.useFile(idle, "/workspace/idle.go")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	timeout := 100 * time.Millisecond
+	go store.expireIdle(ctx, timeout)
+
+	// Requests keep the active session alive past the timeout
+	for deadline := time.Now().Add(3 * timeout); time.Now().Before(deadline); {
+		if terminated, _ := store.Validate(active); terminated {
+			t.Fatal("expected a session sending requests to be kept")
+		}
+		time.Sleep(timeout / 10)
+	}
+
+	expectEnded(t, ended, endedSession{idle, []string{"/workspace/idle.go"}})
+	if terminated, _ := store.Validate(idle); !terminated {
+		t.Error("expected the idle session to be ended")
+	}
+}
+
+func TestSessionStore_NoIdleTimeout(t *testing.T) {
+	store, _ := newTestSessionStore()
+	id := store.Generate()
+
+	// With no timeout expireIdle returns at once rather than ending sessions
+	store.expireIdle(context.Background(), 0)
+	if got := store.idle(time.Now().Add(time.Hour), time.Minute); !reflect.DeepEqual(got, []string{id}) {
+		t.Errorf("expected %s to be idle, got %v", id, got)
+	}
+	if terminated, _ := store.Validate(id); terminated {
+		t.Error("expected the session to be kept")
+	}
+}
@@ -3,11 +3,196 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/vector67/mcp-language-server/internal/lsp"
 	"github.com/vector67/mcp-language-server/internal/tools"
 )
 
+// clientsKey is the context key for the clients a tool call is routed to
+type clientsKey struct{}
+
+// withClients returns a context carrying the clients a tool call is routed to
+func withClients(ctx context.Context, clients []*lsp.Client) context.Context {
+	return context.WithValue(ctx, clientsKey{}, clients)
+}
+
+// clientsFromContext returns the clients a tool call is routed to
+func clientsFromContext(ctx context.Context) []*lsp.Client {
+	clients, _ := ctx.Value(clientsKey{}).([]*lsp.Client)
+	return clients
+}
+
+// clientFromContext returns the client a tool call about a file is routed
+// to, or nil if there is none
+func clientFromContext(ctx context.Context) *lsp.Client {
+	clients := clientsFromContext(ctx)
+	if len(clients) == 0 {
+		return nil
+	}
+	return clients[0]
+}
+
+// serverOptionalTools work on files without a language server, and only tell
+// the server about the file if there is one
+var serverOptionalTools = map[string]bool{
+	"edit_file": true,
+}
+
+// toolMethods maps each tool to the request it needs the language server to
+// support. Tools that aren't listed only use requests every server answers.
+var toolMethods = map[string]string{
+	"definition":        "workspace/symbol",
+	"references":        "textDocument/references",
+	"hover":             "textDocument/hover",
+	"rename_symbol":     "textDocument/rename",
+	"callers":           "textDocument/prepareCallHierarchy",
+	"callees":           "textDocument/prepareCallHierarchy",
+	"content":           "textDocument/documentSymbol",
+	"code_actions":      "textDocument/codeAction",
+	"apply_code_action": "textDocument/codeAction",
+	"format_document":   "textDocument/formatting",
+	"format_range":      "textDocument/rangeFormatting",
+	"workspace_symbols": "workspace/symbol",
+	"document_symbols":  "textDocument/documentSymbol",
+	"implementations":   "textDocument/implementation",
+	"type_definition":   "textDocument/typeDefinition",
+	"declaration":       "textDocument/declaration",
+	"type_hierarchy":    "textDocument/prepareTypeHierarchy",
+	"signature_help":    "textDocument/signatureHelp",
+	"completions":       "textDocument/completion",
+	"read_with_hints":   "textDocument/inlayHint",
+}
+
+// routeToServers is a tool middleware that picks the language servers a tool
+// call goes to and passes their clients on in the context. Calls about a file
+// go to the server for its language, and calls about a symbol name go to
+// every server. Servers that can't serve the tool are left out, and if none
+// can the call is answered with an explanation instead of sending requests
+// they would reject. Support is checked on every call because servers can
+// register and unregister capabilities while running.
+func (s *mcpServer) routeToServers(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var clients []*lsp.Client
+		if filePath := request.GetString("filePath", ""); filePath != "" {
+			client, err := s.clientFor(filePath)
+			if err != nil && serverOptionalTools[request.Params.Name] {
+				coreLogger.Debug("Running %s without a language server: %v", request.Params.Name, err)
+				return next(withClients(ctx, nil), request)
+			} else if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			clients = []*lsp.Client{client}
+		} else {
+			var err error
+			clients, err = s.allClients()
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		if method, ok := toolMethods[request.Params.Name]; ok {
+			supported := make([]*lsp.Client, 0, len(clients))
+			for _, client := range clients {
+				if client.SupportsMethod(method) {
+					supported = append(supported, client)
+				}
+			}
+			if len(supported) == 0 && len(clients) == 1 {
+				return mcp.NewToolResultError(fmt.Sprintf(
+					"The language server does not support %s, which the %s tool needs", method, request.Params.Name)), nil
+			} else if len(supported) == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf(
+					"None of the language servers support %s, which the %s tool needs", method, request.Params.Name)), nil
+			}
+			clients = supported
+		}
+
+		return next(withClients(ctx, clients), request)
+	}
+}
+
+// forwardProgress is a tool middleware that relays the language servers'
+// progress notifications to the MCP client while a tool runs, if the client
+// asked for progress by sending a progress token with the call
+func (s *mcpServer) forwardProgress(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+			return next(ctx, request)
+		}
+		mcpServer := server.ServerFromContext(ctx)
+		if mcpServer == nil {
+			return next(ctx, request)
+		}
+		token := request.Params.Meta.ProgressToken
+
+		// MCP progress must increase with every notification, while the
+		// server's percentages restart for each operation, so the count of
+		// updates is sent as the progress and the percentage goes in the message
+		var sent atomic.Int64
+		for _, client := range clientsFromContext(ctx) {
+			remove := client.OnProgress(func(update lsp.ProgressUpdate) {
+				params := map[string]any{
+					"progressToken": token,
+					"progress":      sent.Add(1),
+					"message":       update.String(),
+				}
+				if err := mcpServer.SendNotificationToClient(ctx, "notifications/progress", params); err != nil {
+					coreLogger.Debug("Failed to send progress notification: %v", err)
+				}
+			})
+			defer remove()
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// indexingWaitTimeout bounds how long a tool call waits for the language
+// servers to finish ongoing work such as indexing
+const indexingWaitTimeout = 5 * time.Second
+
+// waitForIndexing is a tool middleware that lets the language servers finish
+// ongoing work, such as indexing, before a tool queries them. The wait is
+// bounded; if a server is still busy afterwards the result says so, since
+// it may be incomplete.
+func (s *mcpServer) waitForIndexing(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Editing files doesn't depend on the server's index
+		if request.Params.Name == "edit_file" {
+			return next(ctx, request)
+		}
+
+		clients := clientsFromContext(ctx)
+		deadline := time.Now().Add(indexingWaitTimeout)
+		for _, client := range clients {
+			if !client.WaitForIdle(ctx, time.Until(deadline)) {
+				coreLogger.Debug("Running %s while %s is busy: %v", request.Params.Name, client.Name(), client.WorkInProgress())
+			}
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+
+		var work []string
+		for _, client := range clients {
+			work = append(work, client.WorkInProgress()...)
+		}
+		if len(work) > 0 {
+			result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+				"Note: the language server is still indexing (%s), so these results may be incomplete. Try again once it has finished.",
+				strings.Join(work, "; "))))
+		}
+		return result, nil
+	}
+}
+
 func (s *mcpServer) registerTools() error {
 	coreLogger.Debug("Registering MCP tools")
 
@@ -87,7 +272,7 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing edit_file for file: %s", filePath)
-		response, err := tools.ApplyTextEdits(s.ctx, s.lspClient, filePath, edits)
+		response, err := tools.ApplyTextEdits(ctx, clientFromContext(ctx), filePath, edits)
 		if err != nil {
 			coreLogger.Error("Failed to apply edits: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to apply edits: %v", err)), nil
@@ -111,7 +296,7 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing definition for symbol: %s", symbolName)
-		text, err := tools.ReadDefinition(s.ctx, s.lspClient, symbolName)
+		text, err := tools.ReadDefinition(ctx, clientsFromContext(ctx), symbolName)
 		if err != nil {
 			coreLogger.Error("Failed to get definition: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get definition: %v", err)), nil
@@ -135,7 +320,7 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing references for symbol: %s", symbolName)
-		text, err := tools.FindReferences(s.ctx, s.lspClient, symbolName)
+		text, err := tools.FindReferences(ctx, clientsFromContext(ctx), symbolName)
 		if err != nil {
 			coreLogger.Error("Failed to find references: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to find references: %v", err)), nil
@@ -170,7 +355,7 @@ func (s *mcpServer) registerTools() error {
 		showLineNumbers := request.GetBool("showLineNumbers", true)
 
 		coreLogger.Debug("Executing diagnostics for file: %s", filePath)
-		text, err := tools.GetDiagnosticsForFile(s.ctx, s.lspClient, filePath, contextLines, showLineNumbers)
+		text, err := tools.GetDiagnosticsForFile(ctx, clientFromContext(ctx), filePath, contextLines, showLineNumbers)
 		if err != nil {
 			coreLogger.Error("Failed to get diagnostics: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get diagnostics: %v", err)), nil
@@ -196,7 +381,7 @@ func (s *mcpServer) registerTools() error {
 	// 	}
 	//
 	// 	coreLogger.Debug("Executing get_codelens for file: %s", filePath)
-	// 	text, err := tools.GetCodeLens(s.ctx, s.lspClient, filePath)
+	// 	text, err := tools.GetCodeLens(ctx, clientFromContext(ctx), filePath)
 	// 	if err != nil {
 	// 		coreLogger.Error("Failed to get code lens: %v", err)
 	// 		return mcp.NewToolResultError(fmt.Sprintf("failed to get code lens: %v", err)), nil
@@ -235,7 +420,7 @@ func (s *mcpServer) registerTools() error {
 	// 	}
 	//
 	// 	coreLogger.Debug("Executing execute_codelens for file: %s index: %d", filePath, index)
-	// 	text, err := tools.ExecuteCodeLens(s.ctx, s.lspClient, filePath, index)
+	// 	text, err := tools.ExecuteCodeLens(ctx, clientFromContext(ctx), filePath, index)
 	// 	if err != nil {
 	// 		coreLogger.Error("Failed to execute code lens: %v", err)
 	// 		return mcp.NewToolResultError(fmt.Sprintf("failed to execute code lens: %v", err)), nil
@@ -277,7 +462,7 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing hover for file: %s line: %d column: %d", filePath, line, column)
-		text, err := tools.GetHoverInfo(s.ctx, s.lspClient, filePath, line, column)
+		text, err := tools.GetHoverInfo(ctx, clientFromContext(ctx), filePath, line, column)
 		if err != nil {
 			coreLogger.Error("Failed to get hover information: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get hover information: %v", err)), nil
@@ -328,7 +513,7 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing rename_symbol for file: %s line: %d column: %d newName: %s", filePath, line, column, newName)
-		text, err := tools.RenameSymbol(s.ctx, s.lspClient, filePath, line, column, newName)
+		text, err := tools.RenameSymbol(ctx, clientFromContext(ctx), filePath, line, column, newName)
 		if err != nil {
 			coreLogger.Error("Failed to rename symbol: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
@@ -351,7 +536,7 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing callers for symbol: %s", symbolName)
-		text, err := tools.GetCallers(s.ctx, s.lspClient, symbolName, 1)
+		text, err := tools.GetCallers(ctx, clientsFromContext(ctx), symbolName, 1)
 		if err != nil {
 			coreLogger.Error("Failed to find callers: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to find callers: %v", err)), nil
@@ -374,7 +559,7 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing callees for symbol: %s", symbolName)
-		text, err := tools.GetCallees(s.ctx, s.lspClient, symbolName, 1)
+		text, err := tools.GetCallees(ctx, clientsFromContext(ctx), symbolName, 1)
 		if err != nil {
 			coreLogger.Error("Failed to find callees: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to find callees: %v", err)), nil
@@ -416,7 +601,7 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing content for file: %s line: %d column: %d", filePath, line, column)
-		text, err := tools.GetContentInfo(s.ctx, s.lspClient, filePath, line, column)
+		text, err := tools.GetContentInfo(ctx, clientFromContext(ctx), filePath, line, column)
 		if err != nil {
 			coreLogger.Error("Failed to get content information: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get content: %v", err)), nil
@@ -424,6 +609,551 @@ func (s *mcpServer) registerTools() error {
 		return mcp.NewToolResultText(text), nil
 	})
 
+	codeActionsTool := mcp.NewTool("code_actions",
+		mcp.WithDescription("List the refactorings and quick fixes (extract function, fill struct, organize imports, add missing import, etc.) the language server offers for a range of a file. Use apply_code_action with the same range and an index from this list to run one."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("The start line of the range (1-indexed)"),
+		),
+		mcp.WithNumber("startColumn",
+			mcp.Required(),
+			mcp.Description("The start column of the range (1-indexed)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Description("The end line of the range (1-indexed). Defaults to startLine"),
+		),
+		mcp.WithNumber("endColumn",
+			mcp.Description("The end column of the range (1-indexed). Defaults to startColumn"),
+		),
+	)
+
+	s.mcpServer.AddTool(codeActionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		startLine, err := request.RequireInt("startLine")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		startColumn, err := request.RequireInt("startColumn")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		endLine := request.GetInt("endLine", startLine)
+		endColumn := request.GetInt("endColumn", startColumn)
+
+		coreLogger.Debug("Executing code_actions for file: %s range: L%d:C%d - L%d:C%d", filePath, startLine, startColumn, endLine, endColumn)
+		text, err := tools.GetCodeActions(ctx, clientFromContext(ctx), filePath, startLine, startColumn, endLine, endColumn)
+		if err != nil {
+			coreLogger.Error("Failed to get code actions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get code actions: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	applyCodeActionTool := mcp.NewTool("apply_code_action",
+		mcp.WithDescription("Apply a code action returned by code_actions. Pass the same file and range that were given to code_actions together with the index of the action to apply. Edits are written to disk."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("The start line of the range (1-indexed)"),
+		),
+		mcp.WithNumber("startColumn",
+			mcp.Required(),
+			mcp.Description("The start column of the range (1-indexed)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Description("The end line of the range (1-indexed). Defaults to startLine"),
+		),
+		mcp.WithNumber("endColumn",
+			mcp.Description("The end column of the range (1-indexed). Defaults to startColumn"),
+		),
+		mcp.WithNumber("index",
+			mcp.Required(),
+			mcp.Description("The index of the code action to apply (from code_actions output), 1 indexed"),
+		),
+	)
+
+	s.mcpServer.AddTool(applyCodeActionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		startLine, err := request.RequireInt("startLine")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		startColumn, err := request.RequireInt("startColumn")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		endLine := request.GetInt("endLine", startLine)
+		endColumn := request.GetInt("endColumn", startColumn)
+
+		index, err := request.RequireInt("index")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing apply_code_action for file: %s range: L%d:C%d - L%d:C%d index: %d", filePath, startLine, startColumn, endLine, endColumn, index)
+		text, err := tools.ApplyCodeAction(ctx, clientFromContext(ctx), filePath, startLine, startColumn, endLine, endColumn, index)
+		if err != nil {
+			coreLogger.Error("Failed to apply code action: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to apply code action: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	formatDocumentTool := mcp.NewTool("format_document",
+		mcp.WithDescription("Format a file using the language server's formatter (gofmt, prettier, rustfmt, etc.) and write the result to disk. Indentation settings are taken from .editorconfig or the file's existing indentation."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to format"),
+		),
+	)
+
+	s.mcpServer.AddTool(formatDocumentTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing format_document for file: %s", filePath)
+		text, err := tools.FormatDocument(ctx, clientFromContext(ctx), filePath)
+		if err != nil {
+			coreLogger.Error("Failed to format document: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to format document: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	formatRangeTool := mcp.NewTool("format_range",
+		mcp.WithDescription("Format a range of lines in a file using the language server's formatter and write the result to disk."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to format"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("Start line to format, inclusive, one-indexed"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Required(),
+			mcp.Description("End line to format, inclusive, one-indexed"),
+		),
+	)
+
+	s.mcpServer.AddTool(formatRangeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		startLine, err := request.RequireInt("startLine")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		endLine, err := request.RequireInt("endLine")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing format_range for file: %s lines: %d-%d", filePath, startLine, endLine)
+		text, err := tools.FormatRange(ctx, clientFromContext(ctx), filePath, startLine, endLine)
+		if err != nil {
+			coreLogger.Error("Failed to format range: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to format range: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	workspaceSymbolsTool := mcp.NewTool("workspace_symbols",
+		mcp.WithDescription("Search the workspace for symbols whose names fuzzy-match a query. Use this to discover the names of functions, types and other symbols you do not already know. Returns each match with its kind, container and location."),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The text to search for. Matching is fuzzy and performed by the language server"),
+		),
+		mcp.WithArray("kinds",
+			mcp.Description("Only return symbols of these kinds (e.g. 'Function', 'Method', 'Struct', 'Interface', 'Class', 'Constant')"),
+			mcp.Items(map[string]any{
+				"type": "string",
+			}),
+		),
+		mcp.WithString("pathGlob",
+			mcp.Description("Only return symbols in files matching this glob (e.g. 'internal/**/*.go')"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of results to return. Defaults to %d", tools.DefaultWorkspaceSymbolLimit)),
+		),
+	)
+
+	s.mcpServer.AddTool(workspaceSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		query, err := request.RequireString("query")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		kinds := request.GetStringSlice("kinds", nil)
+		pathGlob := request.GetString("pathGlob", "")
+		limit := request.GetInt("limit", tools.DefaultWorkspaceSymbolLimit)
+
+		coreLogger.Debug("Executing workspace_symbols for query: %s kinds: %v pathGlob: %s limit: %d", query, kinds, pathGlob, limit)
+		text, err := tools.SearchWorkspaceSymbols(ctx, clientsFromContext(ctx), query, kinds, pathGlob, limit)
+		if err != nil {
+			coreLogger.Error("Failed to search workspace symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search workspace symbols: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	documentSymbolsTool := mcp.NewTool("document_symbols",
+		mcp.WithDescription("Get an outline of a file: every symbol (type, function, method, field, etc.) with its kind, detail and line range, nested by containment. Use this to understand the structure of a large file before reading it."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to outline"),
+		),
+	)
+
+	s.mcpServer.AddTool(documentSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing document_symbols for file: %s", filePath)
+		text, err := tools.GetDocumentSymbols(ctx, clientFromContext(ctx), filePath)
+		if err != nil {
+			coreLogger.Error("Failed to get document symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get document symbols: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	implementationsTool := mcp.NewTool("implementations",
+		mcp.WithDescription("Find every concrete implementation of an interface, abstract class or method and return its source code. Identify the symbol either by name or by a position in a file."),
+		mcp.WithString("symbolName",
+			mcp.Description("The name of the interface or method whose implementations you want to find (e.g. 'mypackage.MyInterface', 'MyInterface.MyMethod'). Required unless filePath, line and column are given"),
+		),
+		mcp.WithString("filePath",
+			mcp.Description("The path to a file containing the symbol, used instead of symbolName"),
+		),
+		mcp.WithNumber("line",
+			mcp.Description("The line number of the symbol in filePath (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Description("The column number of the symbol in filePath (1-indexed)"),
+		),
+	)
+
+	s.mcpServer.AddTool(implementationsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName := request.GetString("symbolName", "")
+		filePath := request.GetString("filePath", "")
+
+		var text string
+		var err error
+		switch {
+		case filePath != "":
+			line, lineErr := request.RequireInt("line")
+			if lineErr != nil {
+				return mcp.NewToolResultError(lineErr.Error()), nil
+			}
+
+			column, columnErr := request.RequireInt("column")
+			if columnErr != nil {
+				return mcp.NewToolResultError(columnErr.Error()), nil
+			}
+
+			coreLogger.Debug("Executing implementations for file: %s line: %d column: %d", filePath, line, column)
+			text, err = tools.FindImplementationsAtPosition(ctx, clientFromContext(ctx), filePath, line, column)
+		case symbolName != "":
+			coreLogger.Debug("Executing implementations for symbol: %s", symbolName)
+			text, err = tools.FindImplementations(ctx, clientsFromContext(ctx), symbolName)
+		default:
+			return mcp.NewToolResultError("either symbolName or filePath, line and column must be provided"), nil
+		}
+		if err != nil {
+			coreLogger.Error("Failed to find implementations: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find implementations: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	typeDefinitionTool := mcp.NewTool("type_definition",
+		mcp.WithDescription("Read the source code of the type of the symbol at the specified position, e.g. the struct or class a variable holds. Returns the complete type definition."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the symbol"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the symbol (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the symbol (1-indexed)"),
+		),
+	)
+
+	s.mcpServer.AddTool(typeDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		line, err := request.RequireInt("line")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		column, err := request.RequireInt("column")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing type_definition for file: %s line: %d column: %d", filePath, line, column)
+		text, err := tools.ReadTypeDefinition(ctx, clientFromContext(ctx), filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get type definition: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get type definition: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	declarationTool := mcp.NewTool("declaration",
+		mcp.WithDescription("Read the source code of the declaration of the symbol at the specified position. In C and C++ this is the prototype in a header, which differs from the definition in a source file."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the symbol"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the symbol (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the symbol (1-indexed)"),
+		),
+	)
+
+	s.mcpServer.AddTool(declarationTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		line, err := request.RequireInt("line")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		column, err := request.RequireInt("column")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing declaration for file: %s line: %d column: %d", filePath, line, column)
+		text, err := tools.ReadDeclaration(ctx, clientFromContext(ctx), filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get declaration: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get declaration: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	typeHierarchyTool := mcp.NewTool("type_hierarchy",
+		mcp.WithDescription("Show the supertypes (base classes, implemented interfaces) and/or subtypes (derived classes, implementations) of a type as a tree with file:line locations."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the type whose hierarchy you want to see (e.g. 'mypackage.MyInterface', 'MyClass')"),
+		),
+		mcp.WithString("direction",
+			mcp.Description("Which way to walk the hierarchy: 'up' for supertypes, 'down' for subtypes or 'both'. Defaults to 'both'"),
+			mcp.Enum(tools.TypeHierarchyUp, tools.TypeHierarchyDown, tools.TypeHierarchyBoth),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("Maximum number of levels to follow in each direction. Defaults to %d", tools.DefaultTypeHierarchyDepth)),
+		),
+	)
+
+	s.mcpServer.AddTool(typeHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, err := request.RequireString("symbolName")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		direction := request.GetString("direction", tools.TypeHierarchyBoth)
+		depth := request.GetInt("depth", tools.DefaultTypeHierarchyDepth)
+
+		coreLogger.Debug("Executing type_hierarchy for symbol: %s direction: %s depth: %d", symbolName, direction, depth)
+		text, err := tools.GetTypeHierarchy(ctx, clientsFromContext(ctx), symbolName, direction, depth)
+		if err != nil {
+			coreLogger.Error("Failed to get type hierarchy: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get type hierarchy: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	signatureHelpTool := mcp.NewTool("signature_help",
+		mcp.WithDescription("Show the signatures of the function being called at the specified position, including every overload, the active parameter and parameter documentation. Use this inside the parentheses of a call to check argument order and types."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the call"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number inside the call (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number inside the call (1-indexed)"),
+		),
+	)
+
+	s.mcpServer.AddTool(signatureHelpTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		line, err := request.RequireInt("line")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		column, err := request.RequireInt("column")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing signature_help for file: %s line: %d column: %d", filePath, line, column)
+		text, err := tools.GetSignatureHelp(ctx, clientFromContext(ctx), filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get signature help: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get signature help: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	completionsTool := mcp.NewTool("completions",
+		mcp.WithDescription("List the completions the language server offers at a position, such as the members of a value after a '.', with their kinds, details and documentation. Pass 'apply' to insert one of the listed completions into the file, along with any imports it needs."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to complete in"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number to complete at (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number to complete at (1-indexed)"),
+		),
+		mcp.WithString("prefix",
+			mcp.Description("Text to type at the position before completing (e.g. 'Pri' to narrow the list to names starting with it). When listing, the file is left unchanged; when applying, the prefix is replaced by the completion"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of completions to list. Defaults to %d", tools.DefaultCompletionLimit)),
+		),
+		mcp.WithNumber("apply",
+			mcp.Description("The index of the completion to insert (1-indexed, from a previous listing with the same position and prefix). When omitted, completions are only listed"),
+		),
+	)
+
+	s.mcpServer.AddTool(completionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		line, err := request.RequireInt("line")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		column, err := request.RequireInt("column")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		prefix := request.GetString("prefix", "")
+		limit := request.GetInt("limit", tools.DefaultCompletionLimit)
+		apply := request.GetInt("apply", 0)
+
+		var text string
+		if apply > 0 {
+			coreLogger.Debug("Executing completions for file: %s line: %d column: %d prefix: %q apply: %d", filePath, line, column, prefix, apply)
+			text, err = tools.ApplyCompletion(ctx, clientFromContext(ctx), filePath, line, column, prefix, apply)
+		} else {
+			coreLogger.Debug("Executing completions for file: %s line: %d column: %d prefix: %q", filePath, line, column, prefix)
+			text, err = tools.GetCompletions(ctx, clientFromContext(ctx), filePath, line, column, prefix, limit)
+		}
+		if err != nil {
+			coreLogger.Error("Failed to get completions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get completions: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	readWithHintsTool := mcp.NewTool("read_with_hints",
+		mcp.WithDescription("Read a range of lines from a file with the language server's inlay hints inlined as «hint»: inferred types of variables, parameter names at call sites, and implicit lifetimes in Rust. Use this instead of hovering over each token to learn the types the source leaves implicit."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to read"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Description("The first line to read (1-indexed). Defaults to 1"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Description("The last line to read (1-indexed, inclusive). Defaults to the end of the file"),
+		),
+	)
+
+	s.mcpServer.AddTool(readWithHintsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, err := request.RequireString("filePath")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		startLine := request.GetInt("startLine", 1)
+		endLine := request.GetInt("endLine", 0)
+
+		coreLogger.Debug("Executing read_with_hints for file: %s lines: %d-%d", filePath, startLine, endLine)
+		text, err := tools.ReadWithHints(ctx, clientFromContext(ctx), filePath, startLine, endLine)
+		if err != nil {
+			coreLogger.Error("Failed to read file with hints: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read file with hints: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
 	coreLogger.Info("Successfully registered all MCP tools")
 	return nil
 }